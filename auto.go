@@ -0,0 +1,90 @@
+package rtcompare
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// EstimatorMean denotes the arithmetic mean. AutoCompare uses it for near-normal data.
+const EstimatorMean Estimator = "mean"
+
+// Thresholds of the decision rule of AutoCompare: a sample counts as near-normal if the absolute values
+// of its skewness and excess kurtosis (see Moments) do not exceed these limits. The limits are deliberately
+// tight, since the median is the safe choice for timings and the mean only pays off for well-behaved data.
+const (
+	AutoMaxSkewness       = 0.5
+	AutoMaxExcessKurtosis = 1.0
+)
+
+// AutoCompare compares A and B like CompareSamples, but picks the estimator from the shape of the data:
+// if both samples are near-normal, i.e. |skewness| <= AutoMaxSkewness and |excess kurtosis| <=
+// AutoMaxExcessKurtosis as computed by Moments, the bootstrap compares the means of the replicates,
+// otherwise their medians. The mean uses all measurements and thus yields tighter confidences for
+// symmetric, light-tailed data, while the median is robust against the long right tail and the outliers
+// typical for timings (e.g. caused by GC pauses or scheduling). If in doubt, the median is chosen.
+//
+// The chosen estimator is recorded in the Estimator field of the results (EstimatorMean or EstimatorMedian),
+// and ObservedDelta is 1 - mean(A)/mean(B) or 1 - median(A)/median(B), respectively. Parameters and errors
+// are as for CompareSamples; the bootstrap uses a CPRNG. relativeGains is not modified.
+func AutoCompare(A, B []float64, relativeGains []float64, resamples uint64) ([]RTcomparisonResult, error) {
+	return autoCompare(A, B, relativeGains, resamples, 0)
+}
+
+// autoCompare implements AutoCompare with a seed as in BootstrapConfidence.
+func autoCompare(A, B []float64, relativeGains []float64, resamples, seed uint64) ([]RTcomparisonResult, error) {
+	if uint64(len(A)) < MinimumDataPoints || uint64(len(B)) < MinimumDataPoints {
+		return []RTcomparisonResult{}, fmt.Errorf("%w: need at least %d measurements for each input", ErrTooFewDataPoints, MinimumDataPoints)
+	}
+	if len(relativeGains) == 0 {
+		relativeGains = []float64{0.0}
+	}
+	gains := slices.Clone(relativeGains)
+	if !nearNormal(A) || !nearNormal(B) {
+		opts := defaultCompareOptions(resamples)
+		opts.Seed = seed
+		return compareSamples(A, B, gains, opts)
+	}
+	slices.Sort(gains)
+
+	observed := relativeDelta(sampleMean(A), sampleMean(B))
+	counts := make([]uint64, len(gains))
+	for i := range resamples {
+		seedA, seedB := replicateSeeds(seed, i)
+		delta := relativeDelta(sampleMean(bootstrapSample(A, seedA)), sampleMean(bootstrapSample(B, seedB)))
+		for k, threshold := range gains {
+			if delta >= threshold {
+				counts[k]++
+			}
+		}
+	}
+
+	result := make([]RTcomparisonResult, 0, len(gains))
+	for k, t := range gains {
+		confidence := math.NaN()
+		if resamples > 0 {
+			confidence = float64(counts[k]) / float64(resamples)
+		}
+		result = append(result, RTcomparisonResult{
+			RelativeSpeedupSampleAvsSampleB: t,
+			Confidence:                      confidence,
+			Estimator:                       EstimatorMean,
+			ObservedDelta:                   observed,
+			ConfidenceLowerBound:            wilsonLowerBound(confidence, resamples),
+		})
+	}
+	return result, nil
+}
+
+// nearNormal reports whether xs is near-normal according to the decision rule of AutoCompare.
+// It returns false if a moment is NaN.
+func nearNormal(xs []float64) bool {
+	_, _, skewness, excessKurtosis := Moments(xs)
+	return math.Abs(skewness) <= AutoMaxSkewness && math.Abs(excessKurtosis) <= AutoMaxExcessKurtosis
+}
+
+// sampleMean returns the arithmetic mean of xs as computed by Statistics.
+func sampleMean(xs []float64) float64 {
+	mean, _, _ := Statistics(xs)
+	return mean
+}
@@ -0,0 +1,70 @@
+package rtcompare
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutoCompareNearNormalUsesMean(t *testing.T) {
+	rng := NewDPRNG(21)
+	A := SyntheticSamples(200, 100, -0.1, 5, &rng)
+	B := SyntheticSamples(200, 100, 0, 5, &rng)
+	assert.True(t, nearNormal(A))
+	assert.True(t, nearNormal(B))
+
+	gains := []float64{0.15, 0, 0.05}
+	origGains := slices.Clone(gains)
+	got, err := autoCompare(A, B, gains, 2_000, 9)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, origGains, gains)
+	if assert.Len(t, got, 3) {
+		for _, r := range got {
+			assert.Equal(t, EstimatorMean, r.Estimator)
+			assert.InDelta(t, 1-sampleMean(A)/sampleMean(B), r.ObservedDelta, 1e-12)
+		}
+		assert.Equal(t, []float64{0, 0.05, 0.15}, []float64{got[0].RelativeSpeedupSampleAvsSampleB, got[1].RelativeSpeedupSampleAvsSampleB, got[2].RelativeSpeedupSampleAvsSampleB})
+		assert.Greater(t, got[0].Confidence, 0.99)
+		assert.Less(t, got[2].Confidence, 0.01)
+	}
+
+	again, err := autoCompare(A, B, gains, 2_000, 9)
+	assert.NoError(t, err)
+	assert.Equal(t, got, again)
+}
+
+func TestAutoCompareSkewedUsesMedian(t *testing.T) {
+	rng := NewDPRNG(22)
+	A := SyntheticSamples(100, 100, -0.1, 2, &rng)
+	B := SyntheticSamples(100, 100, 0, 2, &rng)
+	// a few slow runs give A a long right tail
+	for i := range 5 {
+		A[i] *= 5
+	}
+	assert.False(t, nearNormal(A))
+
+	got, err := autoCompare(A, B, []float64{0, 0.05}, 1_000, 4)
+	if !assert.NoError(t, err) {
+		return
+	}
+	want, err := CompareSamplesWithOptions(A, B, []float64{0, 0.05}, CompareOptions{Resamples: 1_000, Seed: 4})
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+	for _, r := range got {
+		assert.Equal(t, EstimatorMedian, r.Estimator)
+	}
+}
+
+func TestAutoCompareErrors(t *testing.T) {
+	_, err := AutoCompare(make([]float64, 5), make([]float64, 20), nil, 100)
+	assert.ErrorIs(t, err, ErrTooFewDataPoints)
+
+	got, err := AutoCompare(slices.Repeat([]float64{1}, 20), slices.Repeat([]float64{2}, 20), nil, 100)
+	assert.NoError(t, err)
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, 1.0, got[0].Confidence)
+	}
+}
@@ -0,0 +1,30 @@
+// Package benchresult converts the results of programmatic testing.Benchmark calls into measurements for
+// rtcompare. It is a separate package so that importing rtcompare does not link the testing package into
+// every binary.
+package benchresult
+
+import "testing"
+
+// Samples converts the results of programmatic testing.Benchmark calls into one
+// measurement per result, in nanoseconds per operation, ready for rtcompare.CompareSamples:
+//
+//	var rs []testing.BenchmarkResult
+//	for range 30 {
+//		rs = append(rs, testing.Benchmark(benchmarkA))
+//	}
+//	timesA := benchresult.Samples(rs)
+//
+// The values are those of BenchmarkResult.NsPerOp, but computed as float64 T/N, so the fractions of a
+// nanosecond that NsPerOp truncates are retained; this matters for operations that take only a few
+// nanoseconds. Results with N == 0, which testing.Benchmark returns for a failed or skipped benchmark,
+// are skipped, so the result may be shorter than rs. The input is not modified.
+func Samples(rs []testing.BenchmarkResult) []float64 {
+	result := make([]float64, 0, len(rs))
+	for _, r := range rs {
+		if r.N <= 0 {
+			continue
+		}
+		result = append(result, float64(r.T.Nanoseconds())/float64(r.N))
+	}
+	return result
+}
@@ -0,0 +1,30 @@
+package benchresult
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TomTonic/rtcompare"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSamples(t *testing.T) {
+	rs := []testing.BenchmarkResult{
+		{N: 1000, T: 2500 * time.Nanosecond}, // NsPerOp would truncate 2.5 to 2
+		{N: 0},                               // failed or skipped benchmark
+		{N: 10, T: time.Microsecond},
+	}
+	assert.Equal(t, []float64{2.5, 100}, Samples(rs))
+	assert.Equal(t, int64(100), rs[2].NsPerOp())
+	assert.Empty(t, Samples(nil))
+
+	r := testing.Benchmark(func(b *testing.B) {
+		for b.Loop() {
+			rtcompare.Sink = time.Now()
+		}
+	})
+	samples := Samples([]testing.BenchmarkResult{r})
+	if assert.Len(t, samples, 1) {
+		assert.InDelta(t, float64(r.NsPerOp()), samples[0], 1)
+	}
+}
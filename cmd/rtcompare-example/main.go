@@ -19,57 +19,54 @@ func main() {
 
 	// Initialitze two working arrays
 	workArrayMedian := make([]float64, N)
-	safeState := rng.State
-	fillArray(workArrayMedian, rng) // rng is passed by value here so we should not need to safeguard its state
-	if safeState != rng.State {
-		panic("rng state was modified unexpectedly")
-	}
+	rng.FillFloat64(workArrayMedian)
 	workArrayQuick := make([]float64, N)
-	fillArray(workArrayQuick, rng)
+	rng.FillFloat64(workArrayQuick)
 
 	// Warm-up both methods
 	_ = rtcompare.Median(workArrayMedian)
 	_ = rtcompare.QuickMedian(workArrayQuick)
 
-	// Collect timing samples
-	var timesMedian []float64
-	var timesQuick []float64
-
-	for range repeats {
-		// Set rng to a new state for each timing sample
-		rng = rtcompare.NewDPRNG()
-
-		// make sure to avoid GC noise
-		runtime.GC()
-
-		// Measure Median
-		t1 := rtcompare.SampleTime()
-		// we neet to measure multiple iterations of the function to make sure the time measurement
-		// is not polluted by the timer's resolution too much (quantization noise)
-		for range innerLoops {
-			// Refresh the data in the working array - this function has constant runtime.
-			// Even though Median does not mutate its input we need to do this for the results to be comparable.
-			fillArray(workArrayMedian, rng)
-			_ = rtcompare.Median(workArrayMedian)
-		}
-		t2 := rtcompare.SampleTime()
-		durMedian := float64(rtcompare.DiffTimeStamps(t1, t2)) / float64(innerLoops)
-		timesMedian = append(timesMedian, durMedian)
-
-		// the Median function allocates memory, so we trigger a GC cycle again to reduce noise
-		runtime.GC()
+	// Collect timing samples. Interleave runs both candidates once per repeat in a randomized order,
+	// so neither of them systematically benefits from running second (e.g. with warm caches).
+	measure := map[string]func(){
+		"Median": func() {
+			// Set rng to a new state for each timing sample
+			rng := rtcompare.NewDPRNG()
+			// we neet to measure multiple iterations of the function to make sure the time measurement
+			// is not polluted by the timer's resolution too much (quantization noise)
+			for range innerLoops {
+				// Refresh the data in the working array - FillFloat64 has constant runtime.
+				// Even though Median does not mutate its input we need to do this for the results to be comparable.
+				rng.FillFloat64(workArrayMedian)
+				rtcompare.Sink = rtcompare.Median(workArrayMedian)
+			}
+		},
+		"QuickMedian": func() {
+			rng := rtcompare.NewDPRNG()
+			for range innerLoops {
+				// Refresh the data in the working array - FillFloat64 has constant runtime.
+				// This is necessary as QuickMedian mutates its input. On the other hand, it does not allocate extra memory.
+				rng.FillFloat64(workArrayQuick)
+				rtcompare.Sink = rtcompare.QuickMedian(workArrayQuick)
+			}
+		},
+	}
+	// make sure to avoid GC noise from the setup
+	runtime.GC()
+	// Unlike a hand-written loop, Interleave times each closure as a whole, so a runtime.GC() per sample
+	// would be measured as part of the candidate that calls it. Median allocates memory, so the GC will
+	// run during some samples. Because the order is randomized, these pauses hit both candidates alike
+	// instead of always the one that runs after Median, and the median-based comparison tolerates them
+	// as outliers. Use rtcompare.WithGCOff around Interleave to rule them out at the cost of memory.
+	samples := rtcompare.Interleave(measure, repeats, 0)
 
-		// Measure QuickMedian
-		t3 := rtcompare.SampleTime()
-		for range innerLoops {
-			// Refresh the data in the working array - this function has constant runtime.
-			// This is necessary as QuickMedian mutates its input. On the other hand, it does not allocate extra memory.
-			fillArray(workArrayQuick, rng)
-			_ = rtcompare.QuickMedian(workArrayQuick)
-		}
-		t4 := rtcompare.SampleTime()
-		durQuick := float64(rtcompare.DiffTimeStamps(t3, t4)) / float64(innerLoops)
-		timesQuick = append(timesQuick, durQuick)
+	// Convert the durations of the inner loops to durations per call
+	timesMedian := samples["Median"]
+	timesQuick := samples["QuickMedian"]
+	for i := range repeats {
+		timesMedian[i] /= innerLoops
+		timesQuick[i] /= innerLoops
 	}
 
 	// Compare the timing distributions using bootstrap
@@ -81,16 +78,5 @@ func main() {
 
 	// Report results
 	fmt.Println("⏱️ Runtime comparison: QuickMedian vs. Median for arrays of size", N)
-	for _, r := range results {
-		fmt.Printf("Speedup ≥ %.2f%% → Confidence: %.3f%%\n", r.RelativeSpeedupSampleAvsSampleB*100.0, r.Confidence*100.0)
-	}
-}
-
-// fillArray fills the given array with random float64 values using the provided DPRNG.
-// The function modifies the contents of the array in place.
-// This function has constant runtime for an array of a fixed size as rtcompare.DPRNG generates values in constant time.
-func fillArray(array []float64, rng rtcompare.DPRNG) {
-	for i := range array {
-		array[i] = rng.Float64()
-	}
+	fmt.Print(rtcompare.FormatResults(results))
 }
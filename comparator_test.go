@@ -0,0 +1,90 @@
+package rtcompare
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComparatorMatchesCompareSamplesWithOptions(t *testing.T) {
+	rng := NewDPRNG(11)
+	B := make([]float64, 40)
+	for i := range B {
+		B[i] = 100 + rng.Float64()*10
+	}
+	origB := slices.Clone(B)
+	opts := CompareOptions{Resamples: 2_000, Seed: 5}
+	c, err := NewComparator(B, opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	gains := []float64{0.2, 0, 0.1}
+	origGains := slices.Clone(gains)
+	for _, shift := range []float64{70, 85, 100, 110} {
+		A := make([]float64, 30)
+		for i := range A {
+			A[i] = shift + rng.Float64()*10
+		}
+		got, err := c.CompareAgainst(A, gains)
+		assert.NoError(t, err)
+		want, err := CompareSamplesWithOptions(A, B, slices.Clone(gains), opts)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got, "shift %v", shift)
+	}
+	assert.Equal(t, origB, B)
+	assert.Equal(t, origGains, gains)
+}
+
+func TestComparatorConstantInputs(t *testing.T) {
+	B := slices.Repeat([]float64{100}, 20)
+	A := slices.Repeat([]float64{80}, 20)
+	for _, noShortCircuit := range []bool{false, true} {
+		opts := CompareOptions{Resamples: 500, Seed: 3, NoShortCircuit: noShortCircuit}
+		c, err := NewComparator(B, opts)
+		if !assert.NoError(t, err) {
+			return
+		}
+		got, err := c.CompareAgainst(A, []float64{0.1, 0.3})
+		assert.NoError(t, err)
+		want, err := CompareSamplesWithOptions(A, B, []float64{0.1, 0.3}, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+}
+
+func TestComparatorErrors(t *testing.T) {
+	_, err := NewComparator(make([]float64, 10), CompareOptions{})
+	assert.ErrorIs(t, err, ErrTooFewDataPoints)
+	_, err = NewComparator(make([]float64, 20), CompareOptions{MinimumDataPoints: 2})
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+
+	c, err := NewComparator(make([]float64, 5), CompareOptions{Resamples: 100, MinimumDataPoints: 5})
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = c.CompareAgainst(make([]float64, 4), nil)
+	assert.ErrorIs(t, err, ErrTooFewDataPoints)
+}
+
+func BenchmarkComparator(b *testing.B) {
+	rng := NewDPRNG(1)
+	A := make([]float64, 100)
+	B := make([]float64, 100)
+	for i := range A {
+		A[i] = 90 + rng.Float64()*10
+		B[i] = 100 + rng.Float64()*10
+	}
+	opts := CompareOptions{Resamples: 1_000, Seed: 1}
+	b.Run("CompareSamplesWithOptions", func(b *testing.B) {
+		for b.Loop() {
+			_, _ = CompareSamplesWithOptions(A, B, []float64{0.05}, opts)
+		}
+	})
+	c, _ := NewComparator(B, opts)
+	b.Run("CompareAgainst", func(b *testing.B) {
+		for b.Loop() {
+			_, _ = c.CompareAgainst(A, []float64{0.05})
+		}
+	})
+}
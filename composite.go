@@ -0,0 +1,82 @@
+package rtcompare
+
+import (
+	"math"
+	"slices"
+)
+
+// CompositeScore combines several metrics of the same runs, e.g. the latency and the allocated bytes of
+// each run, into one score per run for a multi-objective comparison with CompareSamples. metrics maps a
+// metric name to its measurements, where metrics[name][i] belongs to run i; weights maps each metric name
+// to its weight. Each metric is normalized by dividing it by its own median, so that metrics of different
+// units and magnitudes become comparable, and the score of run i is
+//
+//	sum over all metrics of weights[name] · metrics[name][i] / median(metrics[name])
+//
+// Since each call normalizes by the medians of its own runs, the scores of two calls are not comparable:
+// a faster implementation would be normalized by its own, smaller median and score just like a slower one.
+// To compare implementation A with baseline B, normalize both by the medians of B instead, see
+// CompositeScoreWithBaseline.
+//
+// All metrics must be non-empty and have the same length (aligned runs), every metric must have a weight
+// and every weight a metric, and the weights must be finite, non-negative and have a positive sum.
+// Returns nil if any of these conditions is violated or a median is zero or not finite. The inputs are
+// not modified.
+func CompositeScore(metrics map[string][]float64, weights map[string]float64) []float64 {
+	baseline := make(map[string]float64, len(metrics))
+	for name, values := range metrics {
+		baseline[name] = Median(values)
+	}
+	return CompositeScoreWithBaseline(metrics, weights, baseline)
+}
+
+// CompositeScoreWithBaseline is CompositeScore with explicit normalization constants: metric name is
+// divided by baseline[name] instead of its own median. Pass the per-metric medians of the baseline
+// implementation to make the scores of several implementations comparable:
+//
+//	base := map[string]float64{"ns": rtcompare.Median(nsB), "bytes": rtcompare.Median(bytesB)}
+//	scoreA := rtcompare.CompositeScoreWithBaseline(metricsA, weights, base)
+//	scoreB := rtcompare.CompositeScoreWithBaseline(metricsB, weights, base)
+//	results, err := rtcompare.CompareSamples(scoreA, scoreB, thresholds, resamples)
+//
+// A composite of the baseline then has a median of about the sum of the weights (exactly so for a single
+// metric). Every metric must have a positive, finite baseline; the other conditions and the results are
+// as for CompositeScore.
+func CompositeScoreWithBaseline(metrics map[string][]float64, weights map[string]float64, baseline map[string]float64) []float64 {
+	if len(metrics) == 0 || len(weights) != len(metrics) {
+		return nil
+	}
+	n := -1
+	var total float64
+	for name, values := range metrics {
+		w, ok := weights[name]
+		if !ok || !(w >= 0) || math.IsInf(w, 0) {
+			return nil
+		}
+		total += w
+		if b := baseline[name]; !(b > 0) || math.IsInf(b, 0) {
+			return nil
+		}
+		if len(values) == 0 || (n >= 0 && len(values) != n) {
+			return nil
+		}
+		n = len(values)
+	}
+	if !(total > 0) || math.IsInf(total, 0) {
+		return nil
+	}
+	// sum in a fixed order, so the scores do not depend on map iteration order
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	scores := make([]float64, n)
+	for _, name := range names {
+		f := weights[name] / baseline[name]
+		for i, x := range metrics[name] {
+			scores[i] += f * x
+		}
+	}
+	return scores
+}
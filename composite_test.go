@@ -0,0 +1,54 @@
+package rtcompare
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompositeScore(t *testing.T) {
+	metrics := map[string][]float64{
+		"ns":    {100, 200, 300},
+		"bytes": {10, 10, 40},
+	}
+	weights := map[string]float64{"ns": 3, "bytes": 1}
+	// medians 200 and 10
+	assert.InDeltaSlice(t, []float64{3*0.5 + 1, 3*1.0 + 1, 3*1.5 + 4}, CompositeScore(metrics, weights), 1e-12)
+	assert.Equal(t, []float64{100, 200, 300}, metrics["ns"], "inputs must not be modified")
+
+	baseline := map[string]float64{"ns": 100, "bytes": 20}
+	assert.InDeltaSlice(t, []float64{3 + 0.5, 6 + 0.5, 9 + 2}, CompositeScoreWithBaseline(metrics, weights, baseline), 1e-12)
+}
+
+func TestCompositeScoreCompare(t *testing.T) {
+	rng := NewDPRNG(31)
+	// A is 20% faster but uses 10% more memory; with latency weighted 3:1 it should win overall
+	metricsA := map[string][]float64{"ns": SyntheticSamples(50, 80, 0, 1, &rng), "bytes": SyntheticSamples(50, 1100, 0, 5, &rng)}
+	metricsB := map[string][]float64{"ns": SyntheticSamples(50, 100, 0, 1, &rng), "bytes": SyntheticSamples(50, 1000, 0, 5, &rng)}
+	weights := map[string]float64{"ns": 3, "bytes": 1}
+	base := map[string]float64{"ns": Median(metricsB["ns"]), "bytes": Median(metricsB["bytes"])}
+	scoreA := CompositeScoreWithBaseline(metricsA, weights, base)
+	scoreB := CompositeScoreWithBaseline(metricsB, weights, base)
+	assert.InDelta(t, 4.0, Median(scoreB), 0.05)
+	assert.InDelta(t, 3*0.8+1.1, Median(scoreA), 0.05)
+	results, err := CompareSamplesWithOptions(scoreA, scoreB, []float64{0.1}, CompareOptions{Resamples: 1000, Seed: 5})
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1.0, results[0].Confidence)
+	}
+}
+
+func TestCompositeScoreInvalid(t *testing.T) {
+	metrics := map[string][]float64{"ns": {1, 2, 3}, "bytes": {4, 5, 6}}
+	weights := map[string]float64{"ns": 1, "bytes": 1}
+	assert.NotNil(t, CompositeScore(metrics, weights))
+
+	assert.Nil(t, CompositeScore(nil, nil))
+	assert.Nil(t, CompositeScore(map[string][]float64{"ns": {1, 2, 3}, "bytes": {4, 5}}, weights), "unaligned lengths")
+	assert.Nil(t, CompositeScore(map[string][]float64{"ns": {}, "bytes": {}}, weights), "empty metrics")
+	assert.Nil(t, CompositeScore(metrics, map[string]float64{"ns": 1}), "missing weight")
+	assert.Nil(t, CompositeScore(metrics, map[string]float64{"ns": 1, "mem": 1}), "weight without metric")
+	assert.Nil(t, CompositeScore(metrics, map[string]float64{"ns": 0, "bytes": 0}), "zero sum")
+	assert.Nil(t, CompositeScore(metrics, map[string]float64{"ns": -1, "bytes": 2}), "negative weight")
+	assert.Nil(t, CompositeScore(map[string][]float64{"ns": {0, 0, 1}, "bytes": {4, 5, 6}}, weights), "zero median")
+	assert.Nil(t, CompositeScoreWithBaseline(metrics, weights, map[string]float64{"ns": 1}), "missing baseline")
+}
@@ -2,8 +2,12 @@ package rtcompare
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/binary"
+	"io"
 	"math"
+	randv2 "math/rand/v2"
+	"sync"
 )
 
 // CPRNG is a cryptographically secure random number generator ("CryptographicPrecisionRNG")
@@ -15,6 +19,7 @@ import (
 type CPRNG struct {
 	bufPos uint32
 	buf    []byte
+	src    io.Reader // source for refilling buf; nil means crypto/rand (used by NewCPRNGFromBytes)
 }
 
 // NewCPRNG creates a new CPRNG with a buffer capacity of capBytes.
@@ -27,24 +32,128 @@ type CPRNG struct {
 // This random number generator is thread-safe as long as each goroutine uses its own instance.
 // This random number generator has a varying memory footprint (usually a few kilobytes).
 func NewCPRNG(capBytes uint32) *CPRNG {
+	b, err := NewCPRNGFromReader(capBytes, rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// NewCPRNGFromReader creates a new CPRNG with a buffer capacity of capBytes that draws its random bytes
+// from r instead of crypto/rand, e.g. from a custom entropy device or, in tests, from a reader with known
+// content. NewCPRNG is equivalent to NewCPRNGFromReader(capBytes, crypto/rand.Reader).
+// Like NewCPRNG, capBytes values below 8 are raised to 8. The buffer is filled once upon creation;
+// if r cannot deliver capBytes bytes, the error is returned (see io.ReadFull).
+// Later refills happen inside methods that cannot return errors, so they panic if r fails.
+// The generated numbers are only as unpredictable as the bytes delivered by r.
+func NewCPRNGFromReader(capBytes uint32, r io.Reader) (*CPRNG, error) {
 	if capBytes < 8 {
 		capBytes = 8 // minimum buffer size to hold at least one uint64
 	}
-	b := &CPRNG{buf: make([]byte, capBytes)}
-	if _, err := rand.Read(b.buf); err != nil {
-		panic(err)
+	b := &CPRNG{buf: getBuffer(capBytes), src: r}
+	if err := b.tryFill(); err != nil {
+		b.Release()
+		return nil, err
+	}
+	return b, nil
+}
+
+// bufferPools holds one *sync.Pool of *[]byte per buffer size, so that buffers released with
+// (*CPRNG).Release can be reused by later CPRNGs of the same size.
+var bufferPools sync.Map // uint32 -> *sync.Pool
+
+// getBuffer returns a buffer of length size, reusing a released one if available. The content of
+// the buffer is unspecified (zero for reused buffers) and must be overwritten before use.
+func getBuffer(size uint32) []byte {
+	if p, ok := bufferPools.Load(size); ok {
+		if bp, ok := p.(*sync.Pool).Get().(*[]byte); ok {
+			return *bp
+		}
+	}
+	return make([]byte, size)
+}
+
+// Release zeroes the buffer of c, so that no previously generated random bytes linger in memory, and
+// returns it to a pool from which NewCPRNG and NewCPRNGFromReader take the buffers of new CPRNGs of the
+// same size. Calling Release is optional; it avoids most buffer allocations in code that creates many
+// short-lived CPRNGs. c must not be used after Release; calling Release more than once is harmless.
+func (c *CPRNG) Release() {
+	if c.buf == nil {
+		return
+	}
+	buf := c.buf
+	clear(buf)
+	c.buf = nil
+	c.bufPos = 0
+	size := uint32(len(buf))
+	p, _ := bufferPools.LoadOrStore(size, &sync.Pool{})
+	p.(*sync.Pool).Put(&buf)
+}
+
+// NewCPRNGFromBytes creates a new CPRNG whose buffer initially holds a copy of buf instead of bytes
+// from crypto/rand. Until these bytes are used up, the generated values are fully determined by buf,
+// which makes it possible to write exact assertions against the CPRNG methods in tests (e.g. to verify
+// how many bytes a method consumes). Values are decoded in little-endian byte order.
+//
+// Once fewer bytes remain than the next call needs, the remaining bytes are discarded and the buffer
+// (of size len(buf)) is refilled from crypto/rand as usual, i.e. the output is no longer deterministic.
+// The function panics if buf is shorter than 8 bytes, the minimum buffer size of a CPRNG.
+// Do not use this constructor for anything that needs unpredictable random numbers.
+func NewCPRNGFromBytes(buf []byte) *CPRNG {
+	if len(buf) < 8 {
+		panic("NewCPRNGFromBytes needs at least 8 bytes")
 	}
+	b := &CPRNG{buf: make([]byte, len(buf))}
+	copy(b.buf, buf)
 	b.bufPos = 0
 	return b
 }
 
+// DeterministicCPRNGBufferSize is the buffer size in bytes of CPRNGs created by NewDeterministicCPRNG.
+const DeterministicCPRNGBufferSize = 8192
+
+// NewDeterministicCPRNG creates a new CPRNG that produces a reproducible sequence of numbers
+// derived from seed. Instead of reading crypto/rand, it fills its buffer with the keystream of a
+// ChaCha8 stream cipher (see math/rand/v2.ChaCha8) keyed with the SHA-256 hash of seed. The output
+// thus has the statistical quality of a cryptographically strong generator, while the same seed
+// always yields the same sequence, on every platform and across program runs.
+//
+// Seeds of any length are accepted, including an empty one. Note that the output is only as
+// unpredictable as the seed: use NewCPRNG whenever unpredictability is required, and use a DPRNG
+// when speed and a constant runtime matter more than statistical quality.
+func NewDeterministicCPRNG(seed []byte) *CPRNG {
+	key := sha256.Sum256(seed)
+	b := &CPRNG{buf: make([]byte, DeterministicCPRNGBufferSize), src: randv2.NewChaCha8(key)}
+	b.fill()
+	return b
+}
+
+// fill refills the whole buffer from the source of c and resets the read position
+func (c *CPRNG) fill() {
+	if err := c.tryFill(); err != nil {
+		panic(err)
+	}
+}
+
+// tryFill is like fill but returns the error of the source instead of panicking
+func (c *CPRNG) tryFill() error {
+	var err error
+	if c.src == nil {
+		_, err = rand.Read(c.buf)
+	} else {
+		_, err = io.ReadFull(c.src, c.buf)
+	}
+	if err != nil {
+		return err
+	}
+	c.bufPos = 0
+	return nil
+}
+
 // ensure that n bytes are available, otherwise refill the buffer
 func (c *CPRNG) ensure(n int) {
 	if c.bufPos+uint32(n) > uint32(len(c.buf)) {
-		if _, err := rand.Read(c.buf); err != nil {
-			panic(err)
-		}
-		c.bufPos = 0
+		c.fill()
 	}
 }
 
@@ -149,6 +258,26 @@ func (c *CPRNG) Float64() float64 {
 	return v
 }
 
+// Float64Full returns a uniformly distributed float64 in [0.0, 1.0) with 53 bits of granularity: the result
+// is k · 2^-53 for the top 53 bits k of the next Uint64 value. Float64 fixes the exponent and fills the 52
+// explicit mantissa bits, so its values are spaced 2^-52 apart; Float64Full uses the implicit bit as well,
+// so its values are spaced 2^-53 apart (2^53 distinct values). Both are exactly uniform on their grid, but
+// Float64Full resolves probabilities half as small, e.g. for fine-grained inverse-CDF sampling in the tails.
+// Float64Full is identical to DPRNG.Float64 and consumes 8 bytes like Float64.
+func (c *CPRNG) Float64Full() float64 {
+	return float64(c.Uint64()>>11) * (1.0 / (1 << 53))
+}
+
+// Float64OpenOpen returns a uniformly distributed float64 in the open interval (0.0, 1.0).
+// It is Float64 shifted by half a grid step: the result is (k + 0.5) · 2^-52 for 52 random bits k, so it
+// lies in [2^-53, 1 - 2^-53] and never returns 0.0 or 1.0. Every value is exactly representable, so the
+// distribution is as uniform as that of Float64. Use it when a zero (or one) at the boundary is degenerate,
+// e.g. as the argument of math.Log for exponentially distributed values.
+// This function consumes 8 bytes like Float64.
+func (c *CPRNG) Float64OpenOpen() float64 {
+	return c.Float64() + 1.0/(1<<53)
+}
+
 // Uint32N returns a non-negative pseudo-random number in the half-open interval [0,n).
 // Use this function for generating random indices or sizes for slices or arrays, for example.
 // Even though this function will probably not be inlined by the compiler, it has a
@@ -156,6 +285,15 @@ func (c *CPRNG) Float64() float64 {
 // This function compensates for bias.
 // For n=0 and n=1, Uint32N returns 0.
 //
+// If n is a power of two, the result is simply the top log2(n) bits of a single random uint32,
+// which is unbiased, so exactly one uint32 is drawn and the rejection step is skipped.
+//
+// Termination: otherwise, a draw is rejected only if the low 32 bits of v*n are below
+// thresh = 2^32 mod n. Since thresh < n, at most n-1 of the 2^32 possible values of v are rejected, so
+// each draw is accepted with probability greater than 1/2 for every n (for n = 2^32-1, thresh = 1 and
+// only v = 0 is rejected). The expected number of draws is therefore below 2, and the probability that
+// the loop needs more than k draws is below 2^-k, even if the random stream repeatedly yields zeros.
+//
 // For implementation details, see:
 //
 //	https://lemire.me/blog/2016/06/27/a-fast-alternative-to-the-modulo-reduction
@@ -163,6 +301,10 @@ func (c *CPRNG) Float64() float64 {
 func (c *CPRNG) Uint32N(n uint32) uint32 {
 	v := c.Uint32()
 	prod := uint64(v) * uint64(n)
+	if n&(n-1) == 0 {
+		// n is zero or a power of two: no bias, no rejection needed
+		return uint32(prod >> 32)
+	}
 	low := uint32(prod)
 	if low < uint32(n) {
 		thresh := uint32(-n) % uint32(n)
@@ -174,3 +316,29 @@ func (c *CPRNG) Uint32N(n uint32) uint32 {
 	}
 	return uint32(prod >> 32)
 }
+
+// Uint64Range returns a pseudo-random uint64 in the closed interval [min, max], e.g. for generating
+// test inputs of bounded magnitude. Like Uint32N, it compensates for bias.
+// If [min, max] covers the full uint64 range, the next Uint64 value is returned unchanged.
+// Uint64Range panics if min > max.
+func (c *CPRNG) Uint64Range(min, max uint64) uint64 {
+	return uint64Range(c, min, max)
+}
+
+// Int32N returns a pseudo-random int32 in the half-open interval [0,n), like Go's math/rand/v2.Int32N.
+// It is Uint32N for a positive n and compensates for bias in the same way. Int32N panics if n <= 0.
+func (c *CPRNG) Int32N(n int32) int32 {
+	if n <= 0 {
+		panic("invalid argument to Int32N")
+	}
+	return int32(c.Uint32N(uint32(n)))
+}
+
+// Int64N returns a pseudo-random int64 in the half-open interval [0,n), like Go's math/rand/v2.Int64N.
+// Like Uint64Range, it compensates for bias. Int64N panics if n <= 0.
+func (c *CPRNG) Int64N(n int64) int64 {
+	if n <= 0 {
+		panic("invalid argument to Int64N")
+	}
+	return int64(uint64n(c, uint64(n)))
+}
@@ -1,6 +1,10 @@
 package rtcompare
 
 import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
 	"math"
 	"os"
 	"runtime"
@@ -275,7 +279,7 @@ func TestCPRNG_Float64_Uniformity(t *testing.T) {
 func TestCPRNG_Uint32N_Bounds(t *testing.T) {
 	c := NewCPRNG(8192)
 	max := ^uint32(0)
-	cases := []uint32{0, 1, 2, 3, 10, 65535, 1 << 31, max}
+	cases := []uint32{0, 1, 2, 3, 4, 10, 64, 65535, 65536, 1 << 31, max - 1, max}
 	for _, n := range cases {
 		samples := 10000
 		if n == 0 || n == 1 {
@@ -296,6 +300,41 @@ func TestCPRNG_Uint32N_Bounds(t *testing.T) {
 	}
 }
 
+// TestCPRNG_Uint32N_PowerOfTwoSingleDraw verifies that Uint32N consumes exactly one
+// uint32 (4 bytes of the buffer) per call if n is a power of two and returns the top bits.
+func TestCPRNG_Uint32N_PowerOfTwoSingleDraw(t *testing.T) {
+	c := NewCPRNG(8192)
+	for k := range 32 {
+		n := uint32(1) << k
+		for range 1000 {
+			c.ensure(4) // make sure the buffer is not refilled within the call
+			pos := c.bufPos
+			expected := uint32((uint64(binary.LittleEndian.Uint32(c.buf[pos:])) << k) >> 32)
+			v := c.Uint32N(n)
+			if c.bufPos != pos+4 {
+				t.Fatalf("Uint32N(%d) consumed %d bytes; want 4", n, c.bufPos-pos)
+			}
+			if v != expected {
+				t.Fatalf("Uint32N(%d) = %d; want %d", n, v, expected)
+			}
+		}
+	}
+}
+
+// TestCPRNG_Uint32N_ZeroStreamTerminates feeds an all-zero buffer into Uint32N, i.e. the worst
+// case for the rejection loop (low = 0 < thresh for every non-power-of-two n), and checks that
+// Uint32N still terminates once the buffer is refilled with random data.
+func TestCPRNG_Uint32N_ZeroStreamTerminates(t *testing.T) {
+	for _, n := range []uint32{3, 10, 1<<31 + 1, ^uint32(0)} {
+		c := NewCPRNG(64)
+		clear(c.buf)
+		c.bufPos = 0
+		if v := c.Uint32N(n); v >= n {
+			t.Fatalf("Uint32N(%d) = %d; out of range", n, v)
+		}
+	}
+}
+
 func TestCPRNG_Uint32N_Uniformity(t *testing.T) {
 	const samples = 5_000_000
 	const alpha = 0.05
@@ -343,7 +382,7 @@ func TestCPRNG_BufferSizePerformance(t *testing.T) {
 			_ = small.Uint64()
 		}
 		t2 := SampleTime()
-		timesSmall = append(timesSmall, float64(DiffTimeStamps(t1, t2))/float64(innerLoops))
+		timesSmall = append(timesSmall, PerOpNanos(t1, t2, innerLoops))
 
 		runtime.GC()
 		t3 := SampleTime()
@@ -351,7 +390,7 @@ func TestCPRNG_BufferSizePerformance(t *testing.T) {
 			_ = large.Uint64()
 		}
 		t4 := SampleTime()
-		timesLarge = append(timesLarge, float64(DiffTimeStamps(t3, t4))/float64(innerLoops))
+		timesLarge = append(timesLarge, PerOpNanos(t3, t4, innerLoops))
 	}
 
 	mSmall := QuickMedian(timesSmall)
@@ -404,7 +443,7 @@ func TestCPRNG_vs_DPRNG_Performance(t *testing.T) {
 			_ = cprng.Uint64()
 		}
 		t2 := SampleTime()
-		timesCprng = append(timesCprng, float64(DiffTimeStamps(t1, t2))/float64(innerLoops))
+		timesCprng = append(timesCprng, PerOpNanos(t1, t2, innerLoops))
 
 		runtime.GC()
 		t3 := SampleTime()
@@ -412,7 +451,7 @@ func TestCPRNG_vs_DPRNG_Performance(t *testing.T) {
 			_ = dprng.Uint64()
 		}
 		t4 := SampleTime()
-		timesDprng = append(timesDprng, float64(DiffTimeStamps(t3, t4))/float64(innerLoops))
+		timesDprng = append(timesDprng, PerOpNanos(t3, t4, innerLoops))
 	}
 
 	mCprng := QuickMedian(timesCprng)
@@ -439,3 +478,317 @@ func TestCPRNG_vs_DPRNG_Performance(t *testing.T) {
 		t.Fatalf("expected confidence >= %.2f for speedup %.1f, got %.3f", minConfidence, res.RelativeSpeedupSampleAvsSampleB, res.Confidence)
 	}
 }
+
+func TestNewCPRNGFromBytes_Deterministic(t *testing.T) {
+	buf := []byte{
+		0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08,
+		0x11, 0x12, 0x13, 0x14,
+		0x21, 0x22,
+		0x31,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	}
+	c := NewCPRNGFromBytes(buf)
+	if v := c.Uint64(); v != 0x0807060504030201 {
+		t.Fatalf("Uint64() = %#x; want 0x0807060504030201", v)
+	}
+	if v := c.Uint32(); v != 0x14131211 {
+		t.Fatalf("Uint32() = %#x; want 0x14131211", v)
+	}
+	if v := c.Uint16(); v != 0x2221 {
+		t.Fatalf("Uint16() = %#x; want 0x2221", v)
+	}
+	if v := c.Uint8(); v != 0x31 {
+		t.Fatalf("Uint8() = %#x; want 0x31", v)
+	}
+	if v := c.Float64(); v != 0.0 {
+		t.Fatalf("Float64() = %v; want 0.0 for zero bytes", v)
+	}
+	if c.bufPos != uint32(len(buf)) {
+		t.Fatalf("expected all %d bytes to be consumed, got %d", len(buf), c.bufPos)
+	}
+
+	buf[0] = 0xFF
+	c2 := NewCPRNGFromBytes(buf[:8])
+	if v := c2.Uint8(); v != 0xFF {
+		t.Fatalf("Uint8() = %#x; want 0xFF", v)
+	}
+}
+
+func TestNewCPRNGFromBytes_CopiesInput(t *testing.T) {
+	buf := make([]byte, 8)
+	c := NewCPRNGFromBytes(buf)
+	buf[0] = 42
+	if v := c.Uint8(); v != 0 {
+		t.Fatalf("CPRNG must not alias the input buffer, got %d", v)
+	}
+}
+
+func TestNewCPRNGFromBytes_Uint32NConsumption(t *testing.T) {
+	// n=3: v=0 is rejected (thresh = 2^32 mod 3 = 1), the second draw v=2^31 yields 1
+	buf := []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x80}
+	c := NewCPRNGFromBytes(buf)
+	if v := c.Uint32N(3); v != 1 {
+		t.Fatalf("Uint32N(3) = %d; want 1", v)
+	}
+	if c.bufPos != 8 {
+		t.Fatalf("expected Uint32N(3) to consume 8 bytes after one rejection, got %d", c.bufPos)
+	}
+}
+
+func TestNewCPRNGFromBytes_TooShort(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected panic for buffer shorter than 8 bytes")
+		}
+	}()
+	NewCPRNGFromBytes(make([]byte, 7))
+}
+
+func TestNewDeterministicCPRNG_Reproducible(t *testing.T) {
+	c1 := NewDeterministicCPRNG([]byte("seed"))
+	c2 := NewDeterministicCPRNG([]byte("seed"))
+	c3 := NewDeterministicCPRNG([]byte("other seed"))
+	equal3 := true
+	// draw more than one buffer's worth of values to cover the refill
+	for i := range 3 * DeterministicCPRNGBufferSize / 8 {
+		v1, v2, v3 := c1.Uint64(), c2.Uint64(), c3.Uint64()
+		if v1 != v2 {
+			t.Fatalf("sequences with the same seed diverge at iteration %d", i)
+		}
+		if v1 != v3 {
+			equal3 = false
+		}
+	}
+	if equal3 {
+		t.Fatalf("expected different sequences for different seeds")
+	}
+	// empty and nil seeds are accepted and equivalent
+	if NewDeterministicCPRNG(nil).Uint64() != NewDeterministicCPRNG([]byte{}).Uint64() {
+		t.Fatalf("nil and empty seeds should yield the same sequence")
+	}
+}
+
+func TestNewDeterministicCPRNG_Uint8_Uniformity(t *testing.T) {
+	const samples = 1 << 20
+	const bins = 256
+	const alpha = 0.01
+	c := NewDeterministicCPRNG([]byte("uniformity"))
+
+	counts := make([]int, bins)
+	for range samples {
+		counts[c.Uint8()]++
+	}
+	x2 := chiSquare(counts, float64(samples)/float64(bins))
+	p := chiSquarePValue(x2, bins-1)
+	if p < alpha {
+		t.Fatalf("χ² test result → H0 rejected (not uniform at significance level α=%.2f): χ²=%.3f p=%.3f", alpha, x2, p)
+	}
+}
+
+func TestCPRNG_Uint64Range(t *testing.T) {
+	c := NewDeterministicCPRNG([]byte("range"))
+	for range 10000 {
+		v := c.Uint64Range(1000, 1999)
+		if v < 1000 || v > 1999 {
+			t.Fatalf("Uint64Range(1000, 1999) = %d out of range", v)
+		}
+	}
+	if v := c.Uint64Range(42, 42); v != 42 {
+		t.Fatalf("Uint64Range(42, 42) = %d; want 42", v)
+	}
+	c1 := NewDeterministicCPRNG([]byte("full"))
+	c2 := NewDeterministicCPRNG([]byte("full"))
+	if got, want := c1.Uint64Range(0, math.MaxUint64), c2.Uint64(); got != want {
+		t.Fatalf("full-range Uint64Range = %d; want raw Uint64 %d", got, want)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected panic for min > max")
+		}
+	}()
+	c.Uint64Range(2, 1)
+}
+
+func TestNewCPRNGFromReader(t *testing.T) {
+	buf := make([]byte, 32)
+	for i := range 4 {
+		binary.LittleEndian.PutUint64(buf[i*8:], uint64(i+1))
+	}
+	c, err := NewCPRNGFromReader(16, bytes.NewReader(buf))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the first 16 bytes are buffered upon creation, the next 16 are read on the first refill
+	for want := uint64(1); want <= 4; want++ {
+		if got := c.Uint64(); got != want {
+			t.Fatalf("Uint64() = %d; want %d", got, want)
+		}
+	}
+	// the reader is exhausted now, so the next refill panics
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected panic when the reader is exhausted")
+		}
+	}()
+	c.Uint64()
+}
+
+func TestNewCPRNGFromReader_Errors(t *testing.T) {
+	if _, err := NewCPRNGFromReader(16, bytes.NewReader(make([]byte, 10))); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected io.ErrUnexpectedEOF for a short read, got %v", err)
+	}
+	if _, err := NewCPRNGFromReader(16, bytes.NewReader(nil)); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected io.EOF for an empty reader, got %v", err)
+	}
+	// capBytes below 8 is raised to 8
+	c, err := NewCPRNGFromReader(1, bytes.NewReader(make([]byte, 8)))
+	if err != nil || len(c.buf) != 8 {
+		t.Fatalf("expected an 8-byte buffer, got err=%v", err)
+	}
+}
+
+func TestCPRNG_Float64OpenOpen(t *testing.T) {
+	buf := make([]byte, 24)
+	binary.LittleEndian.PutUint64(buf[0:], 0)
+	binary.LittleEndian.PutUint64(buf[8:], 0x000FFFFFFFFFFFFF)
+	binary.LittleEndian.PutUint64(buf[16:], 0x0008000000000000)
+	c := NewCPRNGFromBytes(buf)
+	if v := c.Float64OpenOpen(); v != 1.0/(1<<53) {
+		t.Fatalf("smallest value = %v; want 2^-53", v)
+	}
+	if v := c.Float64OpenOpen(); v != 1-1.0/(1<<53) {
+		t.Fatalf("largest value = %v; want 1 - 2^-53", v)
+	}
+	if v := c.Float64OpenOpen(); v != 0.5+1.0/(1<<53) {
+		t.Fatalf("middle value = %v; want 0.5 + 2^-53", v)
+	}
+	r := NewCPRNG(1024)
+	for range 100_000 {
+		if v := r.Float64OpenOpen(); !(v > 0 && v < 1) {
+			t.Fatalf("Float64OpenOpen() = %v out of (0, 1)", v)
+		}
+	}
+}
+
+func TestCPRNG_Release(t *testing.T) {
+	src := bytes.Repeat([]byte{0xAB}, 64)
+	c, err := NewCPRNGFromReader(64, bytes.NewReader(src))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	buf := c.buf
+	c.Release()
+	for i, b := range buf {
+		if b != 0 {
+			t.Fatalf("released buffer not zeroed at %d: %#x", i, b)
+		}
+	}
+	if c.buf != nil {
+		t.Fatalf("Release must detach the buffer from the CPRNG")
+	}
+	c.Release() // harmless
+
+	// new CPRNGs of the same size, possibly reusing the buffer, must be fully refilled
+	for range 10 {
+		d, err := NewCPRNGFromReader(64, bytes.NewReader(src))
+		if err != nil || len(d.buf) != 64 || !bytes.Equal(d.buf, src) {
+			t.Fatalf("expected a refilled 64-byte buffer, got err=%v, buf=%x", err, d.buf)
+		}
+		d.Release()
+	}
+	// a failed construction must not leak entropy either
+	if _, err := NewCPRNGFromReader(64, bytes.NewReader(nil)); err == nil {
+		t.Fatalf("expected an error for an empty reader")
+	}
+}
+
+// BenchmarkCPRNG_CreateHeavy creates a short-lived CPRNG per iteration, as bootstrapSample does for
+// unseeded replicates, with and without returning its buffer to the pool.
+func BenchmarkCPRNG_CreateHeavy(b *testing.B) {
+	b.Run("no-release", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			c := NewCPRNG(8192)
+			Sink = c.Uint64()
+		}
+	})
+	b.Run("release", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			c := NewCPRNG(8192)
+			Sink = c.Uint64()
+			c.Release()
+		}
+	})
+}
+
+func TestCPRNG_Int32N_Int64N(t *testing.T) {
+	c := NewDeterministicCPRNG([]byte("signed"))
+	seen32 := make(map[int32]bool)
+	seen64 := make(map[int64]bool)
+	for range 10000 {
+		v := c.Int32N(6)
+		if v < 0 || v >= 6 {
+			t.Fatalf("Int32N(6) = %d out of range", v)
+		}
+		seen32[v] = true
+		w := c.Int64N(math.MaxInt64/3 + 1)
+		if w < 0 || w > math.MaxInt64/3 {
+			t.Fatalf("Int64N = %d out of range", w)
+		}
+		seen64[w%4] = true
+	}
+	if len(seen32) != 6 || len(seen64) != 4 {
+		t.Fatalf("expected all values to occur, got %d and %d distinct", len(seen32), len(seen64))
+	}
+	for _, n := range []int64{0, -1, math.MinInt64} {
+		for name, f := range map[string]func(){
+			"Int32N": func() { c.Int32N(int32(n)) },
+			"Int64N": func() { c.Int64N(n) },
+		} {
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Fatalf("%s(%d): expected panic", name, n)
+					}
+				}()
+				f()
+			}()
+		}
+	}
+}
+
+func TestCPRNG_Float64Full(t *testing.T) {
+	buf := make([]byte, 32)
+	binary.LittleEndian.PutUint64(buf[0:], 0)
+	binary.LittleEndian.PutUint64(buf[8:], math.MaxUint64)
+	binary.LittleEndian.PutUint64(buf[16:], 1<<11)   // lowest bit of the 53-bit grid
+	binary.LittleEndian.PutUint64(buf[24:], 1<<11-1) // only the low 11 bits set
+	c := NewCPRNGFromBytes(buf)
+	if v := c.Float64Full(); v != 0 {
+		t.Fatalf("smallest value = %v; want 0", v)
+	}
+	if v := c.Float64Full(); v != 1-1.0/(1<<53) {
+		t.Fatalf("largest value = %v; want 1 - 2^-53", v)
+	}
+	if v := c.Float64Full(); v != 1.0/(1<<53) {
+		t.Fatalf("finest step = %v; want 2^-53, half the step of Float64", v)
+	}
+	if v := c.Float64Full(); v != 0 {
+		t.Fatalf("the low 11 bits must be ignored, got %v", v)
+	}
+
+	d := NewDeterministicCPRNG([]byte("full"))
+	ref := NewDeterministicCPRNG([]byte("full"))
+	var sum float64
+	for range 100_000 {
+		v := d.Float64Full()
+		if want := float64(ref.Uint64()>>11) / (1 << 53); v != want {
+			t.Fatalf("Float64Full() = %v; want %v", v, want)
+		}
+		sum += v
+	}
+	if mean := sum / 100_000; math.Abs(mean-0.5) > 0.01 {
+		t.Fatalf("mean %v too far from 0.5", mean)
+	}
+}
@@ -0,0 +1,99 @@
+package rtcompare
+
+import (
+	"math"
+	"slices"
+)
+
+// InputDiagnostics describes one input of a comparison, see DiagnoseComparison.
+type InputDiagnostics struct {
+	// N is the number of measurements.
+	N int `json:"n"`
+	// Median, Mean and StdDev are the median (see QuickMedian), the arithmetic mean and the population standard
+	// deviation (see Statistics) of the measurements.
+	Median float64 `json:"median"`
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stdDev"`
+	// MedianStdErr is the jackknife standard error of the median (see Jackknife), a sensitivity indicator
+	// for how much the median depends on individual measurements.
+	MedianStdErr float64 `json:"medianStdErr"`
+	// Skewness and ExcessKurtosis describe the shape of the distribution, see Moments.
+	Skewness       float64 `json:"skewness"`
+	ExcessKurtosis float64 `json:"excessKurtosis"`
+	// Skewed is true if |Skewness| exceeds AutoMaxSkewness, i.e. if the distribution is clearly asymmetric,
+	// e.g. because of a long tail of slow runs. The median is then the appropriate estimator, and the mean
+	// and standard deviation should be interpreted with care.
+	Skewed bool `json:"skewed"`
+}
+
+// ComparisonDiagnostics is the result of DiagnoseComparison.
+type ComparisonDiagnostics struct {
+	// A and B describe the inputs.
+	A InputDiagnostics `json:"a"`
+	B InputDiagnostics `json:"b"`
+	// ObservedDelta is the observed relative speedup 1 - median(A)/median(B).
+	ObservedDelta float64 `json:"observedDelta"`
+	// DeltaStdErr is the bootstrap standard error of the relative speedup, i.e. the standard deviation of
+	// the relative speedups of the bootstrap replicates (see BootstrapDeltas), ignoring NaN replicates.
+	DeltaStdErr float64 `json:"deltaStdErr"`
+	// Results holds one entry per requested threshold, as returned by CompareSamples with the same seed.
+	Results []RTcomparisonResult `json:"results"`
+}
+
+// DiagnoseComparison bundles everything needed to judge a comparison of A and B at a glance, in particular
+// for small samples: the bootstrap confidences for relativeGains (nil or empty selects the threshold 0)
+// together with the bootstrap standard error of the relative speedup, and for each input its summary
+// statistics, the jackknife standard error of its median and its shape (skewness, excess kurtosis and a
+// Skewed flag).
+//
+// The bootstrap is run once (see BootstrapDeltas); the confidences equal those of CompareSamplesWithOptions
+// with the same resamples and seed. `resamples` and `seed` have the same meaning as `resamples` and
+// `prngSeed` in BootstrapConfidence. Unlike CompareSamples, DiagnoseComparison enforces no minimum number of
+// measurements, since judging small samples is its purpose; values that are undefined for an input, e.g.
+// the jackknife standard error for fewer than two measurements, are math.NaN(). The inputs and relativeGains
+// are not modified.
+func DiagnoseComparison(A, B []float64, relativeGains []float64, resamples, seed uint64) ComparisonDiagnostics {
+	if len(relativeGains) == 0 {
+		relativeGains = []float64{0.0}
+	}
+	gains := slices.Clone(relativeGains)
+	slices.Sort(gains)
+
+	d := ComparisonDiagnostics{A: diagnoseInput(A), B: diagnoseInput(B)}
+	d.ObservedDelta = relativeDelta(d.A.Median, d.B.Median)
+
+	deltas := bootstrapDeltas(A, B, resamples, seed, relativeDelta)
+	conf := make(map[float64]float64, len(gains))
+	for _, t := range gains {
+		conf[t] = math.NaN()
+		if resamples > 0 {
+			count := 0
+			for _, delta := range deltas {
+				if delta >= t {
+					count++
+				}
+			}
+			conf[t] = float64(count) / float64(resamples)
+		}
+	}
+	_, _, d.DeltaStdErr = Statistics(slices.DeleteFunc(deltas, math.IsNaN))
+	if d.DeltaStdErr < 0 {
+		d.DeltaStdErr = math.NaN() // no non-NaN replicates
+	}
+	d.Results = medianResults(gains, conf, d.ObservedDelta, resamples)
+	return d
+}
+
+// diagnoseInput computes the InputDiagnostics of xs.
+func diagnoseInput(xs []float64) InputDiagnostics {
+	d := InputDiagnostics{N: len(xs), Median: QuickMedian(slices.Clone(xs))}
+	var variance float64
+	d.Mean, variance, d.Skewness, d.ExcessKurtosis = Moments(xs)
+	d.StdDev = math.Sqrt(variance)
+	if len(xs) == 0 {
+		d.Mean, d.StdDev = math.NaN(), math.NaN()
+	}
+	_, _, d.MedianStdErr = Jackknife(xs, QuickMedian)
+	d.Skewed = math.Abs(d.Skewness) > AutoMaxSkewness
+	return d
+}
@@ -0,0 +1,69 @@
+package rtcompare
+
+import (
+	"encoding/json"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiagnoseComparison(t *testing.T) {
+	rng := NewDPRNG(41)
+	A := SyntheticSamples(15, 90, 0, 3, &rng)
+	B := SyntheticSamples(15, 100, 0, 3, &rng)
+	A[0] = 300 // one slow run makes A skewed
+	origA := slices.Clone(A)
+	gains := []float64{0.2, 0, 0.1}
+
+	d := DiagnoseComparison(A, B, gains, 2000, 6)
+	assert.Equal(t, origA, A)
+	assert.Equal(t, []float64{0.2, 0, 0.1}, gains)
+
+	want, err := CompareSamplesWithOptions(A, B, slices.Clone(gains), CompareOptions{Resamples: 2000, Seed: 6, MinimumDataPoints: 3})
+	if assert.NoError(t, err) {
+		assert.Equal(t, want, d.Results)
+	}
+	assert.Equal(t, 15, d.A.N)
+	assert.Equal(t, Median(A), d.A.Median)
+	assert.Equal(t, Median(B), d.B.Median)
+	assert.InDelta(t, 1-d.A.Median/d.B.Median, d.ObservedDelta, 1e-15)
+	mean, _, stddev := Statistics(A)
+	assert.Equal(t, mean, d.A.Mean)
+	assert.InDelta(t, stddev, d.A.StdDev, 1e-12)
+	_, _, se := Jackknife(A, QuickMedian)
+	assert.Equal(t, se, d.A.MedianStdErr)
+	assert.True(t, d.A.Skewed)
+	assert.Greater(t, d.A.Skewness, AutoMaxSkewness)
+	assert.False(t, d.B.Skewed)
+
+	deltas := BootstrapDeltas(A, B, 2000, 6)
+	_, _, deltaSE := Statistics(deltas)
+	assert.InDelta(t, deltaSE, d.DeltaStdErr, 1e-12)
+	assert.Greater(t, d.DeltaStdErr, 0.0)
+
+	data, err := json.Marshal(d)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"medianStdErr":`)
+}
+
+func TestDiagnoseComparisonSmallInputs(t *testing.T) {
+	d := DiagnoseComparison([]float64{5}, []float64{10, 11, 12}, nil, 100, 1)
+	assert.Equal(t, 1, d.A.N)
+	assert.Equal(t, 5.0, d.A.Median)
+	assert.True(t, math.IsNaN(d.A.MedianStdErr))
+	if assert.Len(t, d.Results, 1) {
+		assert.Equal(t, 0.0, d.Results[0].RelativeSpeedupSampleAvsSampleB)
+		assert.Equal(t, 1.0, d.Results[0].Confidence)
+	}
+
+	d = DiagnoseComparison(nil, []float64{10, 11, 12}, nil, 100, 1)
+	assert.True(t, math.IsNaN(d.A.Median))
+	assert.True(t, math.IsNaN(d.A.Mean))
+	assert.True(t, math.IsNaN(d.DeltaStdErr))
+	assert.Equal(t, 0.0, d.Results[0].Confidence)
+
+	d = DiagnoseComparison([]float64{1, 2, 3}, []float64{2, 3, 4}, []float64{0}, 0, 1)
+	assert.True(t, math.IsNaN(d.Results[0].Confidence))
+}
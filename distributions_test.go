@@ -0,0 +1,82 @@
+package rtcompare
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalQuantile(t *testing.T) {
+	tests := []struct {
+		p, want float64
+	}{
+		{0.5, 0},
+		{0.975, 1.959963984540054},
+		{0.025, -1.959963984540054},
+		{0.95, 1.6448536269514722},
+		{0.99, 2.3263478740408408},
+	}
+	for _, tc := range tests {
+		if got := NormalQuantile(tc.p); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("NormalQuantile(%v) = %v, want %v", tc.p, got, tc.want)
+		}
+	}
+	if !math.IsInf(NormalQuantile(0), -1) || !math.IsInf(NormalQuantile(1), 1) {
+		t.Errorf("expected ±Inf at the boundaries")
+	}
+	for _, p := range []float64{-0.1, 1.1, math.NaN()} {
+		if !math.IsNaN(NormalQuantile(p)) {
+			t.Errorf("expected NaN for p=%v", p)
+		}
+	}
+}
+
+func TestStudentTQuantile(t *testing.T) {
+	tests := []struct {
+		p, df, want float64
+	}{
+		{0.975, 1, 12.706204736174698},
+		{0.975, 2, 4.302652729749464},
+		{0.975, 10, 2.228138851986274},
+		{0.975, 30, 2.0422724563012373},
+		{0.95, 5, 2.015048372669157},
+		{0.995, 20, 2.845339709776814},
+		{0.025, 10, -2.228138851986274},
+		{0.5, 7, 0},
+	}
+	for _, tc := range tests {
+		if got := StudentTQuantile(tc.p, tc.df); math.Abs(got-tc.want) > 1e-9*math.Max(1, math.Abs(tc.want)) {
+			t.Errorf("StudentTQuantile(%v, %v) = %v, want %v", tc.p, tc.df, got, tc.want)
+		}
+	}
+	// for large df the t-distribution approaches the normal distribution
+	if got, want := StudentTQuantile(0.975, 1e7), NormalQuantile(0.975); math.Abs(got-want) > 1e-6 {
+		t.Errorf("StudentTQuantile for large df = %v, want ≈ %v", got, want)
+	}
+	// df = +Inf is the normal distribution itself
+	for _, p := range []float64{0, 0.001, 0.025, 0.5, 0.975, 1} {
+		if got, want := StudentTQuantile(p, math.Inf(1)), NormalQuantile(p); got != want {
+			t.Errorf("StudentTQuantile(%v, +Inf) = %v, want %v", p, got, want)
+		}
+	}
+	for _, tc := range [][2]float64{{math.Inf(-1), 0}, {-1.96, 0.024997895148220435}, {0, 0.5}, {1.96, 0.9750021048517795}, {math.Inf(1), 1}} {
+		if got := StudentTCDF(tc[0], math.Inf(1)); math.Abs(got-tc[1]) > 1e-12 {
+			t.Errorf("StudentTCDF(%v, +Inf) = %v, want %v", tc[0], got, tc[1])
+		}
+	}
+	for _, args := range [][2]float64{{-0.1, 5}, {1.1, 5}, {0.5, 0}, {0.5, -1}, {math.NaN(), 5}, {0.5, math.NaN()}} {
+		if !math.IsNaN(StudentTQuantile(args[0], args[1])) {
+			t.Errorf("expected NaN for StudentTQuantile(%v, %v)", args[0], args[1])
+		}
+	}
+}
+
+func TestStudentTCDFRoundTrip(t *testing.T) {
+	for _, df := range []float64{1, 2.5, 4, 15, 100} {
+		for _, p := range []float64{0.001, 0.1, 0.3, 0.5, 0.7, 0.9, 0.999} {
+			q := StudentTQuantile(p, df)
+			if got := StudentTCDF(q, df); math.Abs(got-p) > 1e-10 {
+				t.Errorf("StudentTCDF(StudentTQuantile(%v, %v)) = %v", p, df, got)
+			}
+		}
+	}
+}
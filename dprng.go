@@ -1,6 +1,7 @@
 package rtcompare
 
 import (
+	"math"
 	"math/bits"
 	"math/rand"
 )
@@ -12,12 +13,16 @@ import (
 // This random number generator is deterministic in its runtime (i.e., it has a constant runtime).
 // This random number generator is not cryptographically secure.
 // This random number generator is thread-safe as long as each goroutine uses its own instance.
-// This random number generator has a very small memory footprint (24 bytes).
+// This random number generator has a very small memory footprint (40 bytes, including the cached
+// second value of NormFloat64).
 // The initial state must not be zero.
 type DPRNG struct {
 	State     uint64
 	Scrambler uint64
 	Round     uint64 // for debugging purposes
+
+	norm       float64 // second value of the last Box-Muller pair, returned by the next NormFloat64
+	normCached bool
 }
 
 const vigna = uint64(0x2545F4914F6CDD1D) // Vigna's default scrambler constant optimized for our 12/25/27 xorshift
@@ -51,6 +56,15 @@ func NewDPRNG(seed ...uint64) DPRNG {
 	return result
 }
 
+// NewDPRNGChecked is like NewDPRNG(seed), but also reports whether the seed was replaced because it was
+// zero. A replaced seed means that the generator was initialized with a random state, so its sequence is
+// not reproducible; check the flag when a supposedly seeded run does not reproduce, e.g. because a seed
+// variable was never set. The scrambler constant is Vigna's default, as for NewDPRNG with a single seed.
+func NewDPRNGChecked(seed uint64) (rng *DPRNG, replaced bool) {
+	r := NewDPRNG(seed)
+	return &r, seed == 0
+}
+
 // GenerateScrambler generates reasonable scrambler constants for the DPRNG.
 // The generated scrambler constant is always an odd number with a good bit density.
 // This ensures maximal period and good mixing properties.
@@ -97,12 +111,60 @@ func (thisState *DPRNG) Float64() float64 {
 	return float64(u64>>11) * (1.0 / (1 << 53)) // use the top 53 bits for a float64 in [0.0, 1.0)
 }
 
+// Float64Full returns the same value as Float64, which already uses the full 53 bits of granularity
+// (values spaced 2^-53 apart, see CPRNG.Float64Full). It exists so that code can use the same method name
+// for both generators when it depends on this resolution.
+func (thisState *DPRNG) Float64Full() float64 {
+	return thisState.Float64()
+}
+
+// Float64OpenOpen returns a pseudo-random float64 in the open interval (0.0, 1.0), whereas Float64
+// returns values in the half-open interval [0.0, 1.0).
+// The result is (k + 0.5) · 2^-52, where k are the top 52 bits of the next Uint64 value, i.e. it lies in
+// [2^-53, 1 - 2^-53] and never returns 0.0 or 1.0. Adding half a grid step instead of rejecting zero keeps
+// the runtime deterministic (i.e. constant), and every value is exactly representable, so the distribution
+// is uniform with an effective precision of 52 bits. Use it when a zero (or one) at the boundary is
+// degenerate, e.g. as the argument of math.Log for exponentially distributed values.
+func (thisState *DPRNG) Float64OpenOpen() float64 {
+	u64 := thisState.Uint64()
+	return (float64(u64>>12) + 0.5) * (1.0 / (1 << 52))
+}
+
+// NormFloat64 returns a standard normally distributed float64 (mean 0, standard deviation 1), like
+// Go's math/rand.NormFloat64, e.g. for synthetic timing distributions (see SyntheticSamples).
+// It uses the Box-Muller transform: the uniforms u1 = Float64OpenOpen() and u2 = Float64() yield the two
+// independent normals r·cos(2πu2) and r·sin(2πu2) with r = sqrt(-2·ln(u1)). The first is returned and
+// the second is cached for the next call.
+//
+// Consumption of the sequence: every odd call (the 1st, 3rd, ...) consumes exactly two Uint64 values,
+// every even call consumes none and returns the cached value. n calls therefore advance State by
+// 2·ceil(n/2) steps, and the sequence is deterministic for a given seed. Note that the cached value is
+// not part of State: interleaving NormFloat64 with other methods keeps the cache, and copying State to
+// another generator only reproduces the sequence after an even number of NormFloat64 calls. Seed clears
+// the cache.
+func (thisState *DPRNG) NormFloat64() float64 {
+	if thisState.normCached {
+		thisState.normCached = false
+		return thisState.norm
+	}
+	u1 := thisState.Float64OpenOpen() // never 0, so the logarithm is finite
+	u2 := thisState.Float64()
+	r := math.Sqrt(-2 * math.Log(u1))
+	sin, cos := math.Sincos(2 * math.Pi * u2)
+	thisState.norm = r * sin
+	thisState.normCached = true
+	return r * cos
+}
+
 // UInt32N returns a pseudo-random uint32 in the range [0, n) like Go’s math/rand.Intn().
 // Use this function for generating random indices or sizes for slices or arrays, for example.
 // This code avoids modulo arithmetics by implementing Lemire's fast alternative to the modulo reduction
 // method (see https://lemire.me/blog/2016/06/27/a-fast-alternative-to-the-modulo-reduction/).
 // It has a deterministic (i.e. constant) runtime and a high probability to be inlined by the compiler.
 // Note: This implementation may introduce a slight bias if n is not a power of two.
+// If n is a power of two 2^k, the result is exactly the top k bits of the next Uint64 value, which is
+// unbiased. No special case is needed for that: the single multiplication already yields this result,
+// so the runtime stays constant and the generated sequence is the same for all n.
 func (thisState *DPRNG) UInt32N(n uint32) uint32 {
 	u64 := thisState.Uint64()
 	hi, _ := bits.Mul64(u64, uint64(n))
@@ -110,3 +172,99 @@ func (thisState *DPRNG) UInt32N(n uint32) uint32 {
 	// since n is a uint32 (at most 2^32 - 1), hi is at most 2^32 - 1 and fits in 32 bits
 	return uint32(hi)
 }
+
+// Uint64Range returns a pseudo-random uint64 in the closed interval [min, max], e.g. for generating
+// test inputs of bounded magnitude. Unlike UInt32N, the result is unbiased; the price is that the
+// rejection step may draw more than one value, so the runtime is not strictly constant.
+// If [min, max] covers the full uint64 range, the next Uint64 value is returned unchanged.
+// Uint64Range panics if min > max.
+func (thisState *DPRNG) Uint64Range(min, max uint64) uint64 {
+	return uint64Range(thisState, min, max)
+}
+
+// Int32N returns a pseudo-random int32 in the half-open interval [0,n), like Go's math/rand/v2.Int32N.
+// It is UInt32N for a positive n without the conversions, so it shares its constant runtime and its
+// slight bias if n is not a power of two. Int32N panics if n <= 0.
+func (thisState *DPRNG) Int32N(n int32) int32 {
+	if n <= 0 {
+		panic("invalid argument to Int32N")
+	}
+	return int32(thisState.UInt32N(uint32(n)))
+}
+
+// Int64N returns a pseudo-random int64 in the half-open interval [0,n), like Go's math/rand/v2.Int64N.
+// Like Uint64Range, the result is unbiased and the runtime is not strictly constant. Int64N panics if n <= 0.
+func (thisState *DPRNG) Int64N(n int64) int64 {
+	if n <= 0 {
+		panic("invalid argument to Int64N")
+	}
+	return int64(uint64n(thisState, uint64(n)))
+}
+
+// Int63 returns a non-negative pseudo-random int64 (the top 63 bits of the next Uint64 value).
+// Together with Uint64 and Seed it makes *DPRNG a math/rand.Source64, see QuickRand.
+func (thisState *DPRNG) Int63() int64 {
+	return int64(thisState.Uint64() >> 1)
+}
+
+// Seed resets the generator to the state seed, keeping the scrambler constant, so that it produces the
+// same sequence as NewDPRNG(uint64(seed), scrambler). Unlike NewDPRNG, which replaces a zero seed with a
+// random one, Seed must be deterministic to satisfy math/rand.Source: a zero seed is replaced by 1.
+func (thisState *DPRNG) Seed(seed int64) {
+	thisState.State = uint64(seed)
+	if thisState.State == 0 {
+		thisState.State = 1
+	}
+	if thisState.Scrambler == 0 {
+		thisState.Scrambler = vigna
+	}
+	thisState.Round = 0
+	thisState.normCached = false
+}
+
+// QuickRand returns a *math/rand.Rand backed by a DPRNG seeded with seed, e.g. for the Rand field of
+// testing/quick.Config. With a fixed non-zero seed, a property test generates exactly the same inputs in
+// every run, so a failing input can be reproduced by re-running the test:
+//
+//	err := quick.Check(prop, &quick.Config{Rand: rtcompare.QuickRand(42)})
+//
+// As for NewDPRNG, a zero seed is replaced by a random one; log the seed of such runs to reproduce failures.
+// The returned Rand is not thread-safe.
+func QuickRand(seed uint64) *rand.Rand {
+	src := NewDPRNG(seed)
+	return rand.New(&src)
+}
+
+// FillUint64 fills dst with the next len(dst) pseudo-random numbers in the sequence.
+// The result is identical to calling Uint64 len(dst) times, but the state is kept in a local
+// variable for the whole loop, avoiding the per-call overhead when generating large amounts of data.
+// It has a deterministic (i.e. constant) runtime for a given len(dst).
+func (thisState *DPRNG) FillUint64(dst []uint64) {
+	x := thisState.State
+	scrambler := thisState.Scrambler
+	for i := range dst {
+		x ^= x >> 12
+		x ^= x << 25
+		x ^= x >> 27
+		dst[i] = x * scrambler
+	}
+	thisState.State = x
+	thisState.Round += uint64(len(dst))
+}
+
+// FillFloat64 fills dst with len(dst) pseudo-random float64 values in the range [0.0, 1.0).
+// The result is identical to calling Float64 len(dst) times, but the loop is tight and branch-free,
+// so it has a deterministic (i.e. constant) runtime for a given len(dst). This makes it suitable for
+// refreshing input data inside a timed section, where the preparation must not bias the comparison.
+func (thisState *DPRNG) FillFloat64(dst []float64) {
+	x := thisState.State
+	scrambler := thisState.Scrambler
+	for i := range dst {
+		x ^= x >> 12
+		x ^= x << 25
+		x ^= x >> 27
+		dst[i] = float64((x*scrambler)>>11) * (1.0 / (1 << 53))
+	}
+	thisState.State = x
+	thisState.Round += uint64(len(dst))
+}
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"math"
 	"math/bits"
+	"math/rand"
+	"reflect"
 	"testing"
+	"testing/quick"
 
 	set3 "github.com/TomTonic/Set3"
 	"github.com/stretchr/testify/assert"
@@ -323,3 +326,271 @@ func dMaxUint32(s []uint32) uint32 {
 	min, max := minMax(s...)
 	return max - min
 }
+
+func TestFillUint64_MatchesUint64(t *testing.T) {
+	for _, n := range []int{0, 1, 7, 1000} {
+		rng1 := NewDPRNG(0x1234567890ABCDEF)
+		rng2 := NewDPRNG(0x1234567890ABCDEF)
+		dst := make([]uint64, n)
+		rng1.FillUint64(dst)
+		for i := range dst {
+			if v := rng2.Uint64(); dst[i] != v {
+				t.Fatalf("n=%d: mismatch at index %d: %d vs %d", n, i, dst[i], v)
+			}
+		}
+		assert.Equal(t, rng2.State, rng1.State, "state out of sync after FillUint64")
+		assert.Equal(t, rng2.Round, rng1.Round, "round out of sync after FillUint64")
+		// both generators must continue with the same sequence
+		assert.Equal(t, rng2.Uint64(), rng1.Uint64())
+	}
+}
+
+func BenchmarkDPRNG_Uint64Loop(b *testing.B) {
+	rng := NewDPRNG(0x1234567890ABCDEF)
+	dst := make([]uint64, 1<<22)
+	b.SetBytes(int64(len(dst) * 8))
+	for b.Loop() {
+		for i := range dst {
+			dst[i] = rng.Uint64()
+		}
+	}
+}
+
+func BenchmarkDPRNG_FillUint64(b *testing.B) {
+	rng := NewDPRNG(0x1234567890ABCDEF)
+	dst := make([]uint64, 1<<22)
+	b.SetBytes(int64(len(dst) * 8))
+	for b.Loop() {
+		rng.FillUint64(dst)
+	}
+}
+
+func TestFillFloat64_MatchesFloat64(t *testing.T) {
+	for _, n := range []int{0, 1, 7, 1000} {
+		rng1 := NewDPRNG(0x1234567890ABCDEF)
+		rng2 := NewDPRNG(0x1234567890ABCDEF)
+		dst := make([]float64, n)
+		rng1.FillFloat64(dst)
+		for i := range dst {
+			if v := rng2.Float64(); dst[i] != v {
+				t.Fatalf("n=%d: mismatch at index %d: %v vs %v", n, i, dst[i], v)
+			}
+			if dst[i] < 0.0 || dst[i] >= 1.0 {
+				t.Fatalf("FillFloat64 value out of range: %v", dst[i])
+			}
+		}
+		assert.Equal(t, rng2.State, rng1.State, "state out of sync after FillFloat64")
+		assert.Equal(t, rng2.Round, rng1.Round, "round out of sync after FillFloat64")
+	}
+}
+
+func BenchmarkDPRNG_Float64Loop(b *testing.B) {
+	rng := NewDPRNG(0x1234567890ABCDEF)
+	dst := make([]float64, 1<<16)
+	for b.Loop() {
+		for i := range dst {
+			dst[i] = rng.Float64()
+		}
+	}
+}
+
+func BenchmarkDPRNG_FillFloat64(b *testing.B) {
+	rng := NewDPRNG(0x1234567890ABCDEF)
+	dst := make([]float64, 1<<16)
+	for b.Loop() {
+		rng.FillFloat64(dst)
+	}
+}
+
+// TestUInt32N_PowerOfTwoIsTopBits verifies that for n = 2^k UInt32N returns exactly
+// the top k bits of the next Uint64 value, i.e. an unbiased value without rejection.
+func TestUInt32N_PowerOfTwoIsTopBits(t *testing.T) {
+	for k := 1; k < 32; k++ {
+		n := uint32(1) << k
+		rng := NewDPRNG(0x1234567890ABCDEF)
+		ref := NewDPRNG(0x1234567890ABCDEF)
+		for range 1000 {
+			got := rng.UInt32N(n)
+			want := uint32(ref.Uint64() >> (64 - k))
+			if got != want {
+				t.Fatalf("UInt32N(%d) = %d; want top %d bits %d", n, got, k, want)
+			}
+		}
+	}
+	rng := NewDPRNG(42)
+	if v := rng.UInt32N(1); v != 0 {
+		t.Fatalf("UInt32N(1) = %d; want 0", v)
+	}
+}
+
+func TestDPRNG_Uint64Range(t *testing.T) {
+	rng := NewDPRNG(0x1234567890ABCDEF)
+	seen := make(map[uint64]bool)
+	for range 10000 {
+		v := rng.Uint64Range(10, 15)
+		assert.True(t, v >= 10 && v <= 15, "Uint64Range(10, 15) = %d out of range", v)
+		seen[v] = true
+	}
+	assert.Len(t, seen, 6, "all values in [10, 15] should occur")
+
+	assert.Equal(t, uint64(7), rng.Uint64Range(7, 7))
+	v := rng.Uint64Range(math.MaxUint64-1, math.MaxUint64)
+	assert.True(t, v >= math.MaxUint64-1)
+
+	// full range returns the raw next Uint64 value
+	ref := rng
+	assert.Equal(t, ref.Uint64(), rng.Uint64Range(0, math.MaxUint64))
+
+	assert.Panics(t, func() { rng.Uint64Range(2, 1) })
+}
+
+func TestDPRNG_Float64OpenOpen(t *testing.T) {
+	rng := NewDPRNG(0x1234567890ABCDEF)
+	var sum float64
+	const n = 100_000
+	for range n {
+		v := rng.Float64OpenOpen()
+		assert.True(t, v > 0 && v < 1, "Float64OpenOpen() = %v out of (0, 1)", v)
+		sum += v
+	}
+	assert.InDelta(t, 0.5, sum/n, 0.01)
+
+	// the extreme inputs map to the boundaries of the grid
+	zero := DPRNG{State: 0, Scrambler: vigna} // a (normally forbidden) zero state yields Uint64() == 0
+	assert.Equal(t, 1.0/(1<<53), zero.Float64OpenOpen())
+	ref := NewDPRNG(42)
+	rng = NewDPRNG(42)
+	u := ref.Uint64()
+	assert.Equal(t, float64(u>>12)/(1<<52)+1.0/(1<<53), rng.Float64OpenOpen())
+	assert.True(t, (float64(1<<52-1)+0.5)/(1<<52) < 1, "largest value must be below 1")
+}
+
+func TestDPRNG_Source64(t *testing.T) {
+	var _ rand.Source64 = (*DPRNG)(nil)
+
+	rng := NewDPRNG(42)
+	ref := NewDPRNG(42)
+	for range 1000 {
+		v := rng.Int63()
+		assert.GreaterOrEqual(t, v, int64(0))
+		assert.Equal(t, int64(ref.Uint64()>>1), v)
+	}
+
+	scrambled := NewDPRNG(1, 0xABCDEF)
+	scrambled.Uint64()
+	scrambled.Seed(7)
+	ref = NewDPRNG(7, 0xABCDEF)
+	assert.Equal(t, uint64(0), scrambled.Round)
+	assert.Equal(t, ref.Uint64(), scrambled.Uint64(), "Seed must keep the scrambler")
+
+	var zero DPRNG
+	zero.Seed(0)
+	ref = NewDPRNG(1)
+	assert.Equal(t, ref.Uint64(), zero.Uint64(), "zero seed must be replaced by 1 deterministically")
+}
+
+func TestQuickRand(t *testing.T) {
+	gen := func(r *rand.Rand) []float64 {
+		v, ok := quick.Value(reflect.TypeOf([]float64{}), r)
+		assert.True(t, ok)
+		return v.Interface().([]float64)
+	}
+	assert.Equal(t, gen(QuickRand(99)), gen(QuickRand(99)), "same seed must generate the same inputs")
+	assert.NotEqual(t, gen(QuickRand(99)), gen(QuickRand(100)))
+
+	var inputs [2][]int
+	for i := range inputs {
+		err := quick.Check(func(x int) bool {
+			inputs[i] = append(inputs[i], x)
+			return true
+		}, &quick.Config{MaxCount: 20, Rand: QuickRand(7)})
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, inputs[0], inputs[1])
+}
+
+func TestNewDPRNGChecked(t *testing.T) {
+	rng, replaced := NewDPRNGChecked(42)
+	assert.False(t, replaced)
+	ref := NewDPRNG(42)
+	assert.Equal(t, ref, *rng)
+
+	rng, replaced = NewDPRNGChecked(0)
+	assert.True(t, replaced)
+	assert.NotEqual(t, uint64(0), rng.State)
+	assert.Equal(t, vigna, rng.Scrambler)
+}
+
+func TestDPRNG_Int32N_Int64N(t *testing.T) {
+	rng := NewDPRNG(0x1234567890ABCDEF)
+	ref := rng
+	for range 1000 {
+		v := rng.Int32N(7)
+		assert.Equal(t, int32(ref.UInt32N(7)), v, "Int32N must match UInt32N")
+		assert.True(t, v >= 0 && v < 7)
+	}
+	seen := make(map[int64]bool)
+	for range 10000 {
+		v := rng.Int64N(5)
+		assert.True(t, v >= 0 && v < 5, "Int64N(5) = %d out of range", v)
+		seen[v] = true
+	}
+	assert.Len(t, seen, 5)
+	assert.Equal(t, int32(0), rng.Int32N(1))
+	v := rng.Int64N(math.MaxInt64)
+	assert.True(t, v >= 0)
+
+	for _, n := range []int64{0, -1, math.MinInt64} {
+		assert.Panics(t, func() { rng.Int64N(n) }, "Int64N(%d)", n)
+		assert.Panics(t, func() { rng.Int32N(int32(n)) }, "Int32N(%d)", int32(n))
+	}
+}
+
+func TestDPRNG_Float64Full(t *testing.T) {
+	rng := NewDPRNG(42)
+	ref := NewDPRNG(42)
+	for range 1000 {
+		assert.Equal(t, ref.Float64(), rng.Float64Full())
+	}
+}
+
+func TestDPRNG_NormFloat64(t *testing.T) {
+	rng := NewDPRNG(0x1234567890ABCDEF)
+	const n = 200_000
+	var s OnlineStats
+	var within1 int
+	for range n {
+		z := rng.NormFloat64()
+		s.Push(z)
+		if math.Abs(z) < 1 {
+			within1++
+		}
+	}
+	assert.InDelta(t, 0, s.Mean(), 0.01)
+	assert.InDelta(t, 1, s.StdDev(), 0.01)
+	assert.InDelta(t, 0.6827, float64(within1)/n, 0.005)
+
+	// each pair of values consumes two Uint64 values and is computed from them by Box-Muller
+	ref := NewDPRNG(42)
+	rng = NewDPRNG(42)
+	u1, u2 := ref.Float64OpenOpen(), ref.Float64()
+	r := math.Sqrt(-2 * math.Log(u1))
+	assert.Equal(t, r*math.Cos(2*math.Pi*u2), rng.NormFloat64())
+	assert.Equal(t, ref.State, rng.State, "the first value consumes two Uint64 values")
+	assert.Equal(t, r*math.Sin(2*math.Pi*u2), rng.NormFloat64())
+	assert.Equal(t, ref.State, rng.State, "the second value comes from the cache")
+	assert.Equal(t, uint64(2), rng.Round)
+
+	// deterministic for a given seed
+	a, b := NewDPRNG(7), NewDPRNG(7)
+	for range 5 {
+		assert.Equal(t, a.NormFloat64(), b.NormFloat64())
+	}
+
+	// Seed clears the cache
+	rng = NewDPRNG(42)
+	rng.NormFloat64()
+	rng.Seed(42)
+	fresh := NewDPRNG(42)
+	assert.Equal(t, fresh.NormFloat64(), rng.NormFloat64())
+}
@@ -0,0 +1,37 @@
+package rtcompare
+
+import "errors"
+
+// Sentinel errors returned (possibly wrapped with additional details) by the functions of this package.
+// Use errors.Is to test for them.
+var (
+	// ErrTooFewDataPoints is returned if an input contains fewer measurements than required,
+	// e.g. fewer than MinimumDataPoints for CompareSamples.
+	ErrTooFewDataPoints = errors.New("not enough data points")
+	// ErrNonFiniteValues is returned by ValidateSamples if an input contains NaN or ±Inf values.
+	ErrNonFiniteValues = errors.New("non-finite values found")
+	// ErrNonPositiveValues is returned by CompareSamplesLog if an input contains zero or negative values, and by
+	// CompareSamplesReport if the median of an input is zero or negative.
+	ErrNonPositiveValues = errors.New("non-positive values found")
+	// ErrEmptySample is returned by ValidateSamples for an empty input, e.g. if no values remain
+	// after removing the non-finite ones with CleanSamples.
+	ErrEmptySample = errors.New("empty sample")
+	// ErrInvalidWeights is returned for weights that do not match their values or are not
+	// finite, non-negative numbers with a positive sum.
+	ErrInvalidWeights = errors.New("invalid weights")
+	// ErrUnpairedSamples is returned by CompareSamplesPaired if the inputs differ in length.
+	ErrUnpairedSamples = errors.New("samples are not paired")
+	// ErrInvalidConfidence is returned for a confidence level that is neither a fraction in [0, 1]
+	// nor a percentage in (1, 100].
+	ErrInvalidConfidence = errors.New("invalid confidence level")
+	// ErrInvalidFactor is returned by CompareSamplesByFactor for a speedup factor that is not positive.
+	ErrInvalidFactor = errors.New("invalid speedup factor")
+	// ErrInvalidOptions is returned by CompareSamplesWithOptions for invalid CompareOptions.
+	ErrInvalidOptions = errors.New("invalid options")
+	// ErrWindowTooShort is returned by Benchmark if a timed window is shorter than MinMeaningfulDuration.
+	ErrWindowTooShort = errors.New("timed window too short")
+	// ErrInvalidResultFormat is returned by ParseResult for a line not produced by RTcomparisonResult.String.
+	ErrInvalidResultFormat = errors.New("invalid result format")
+	// ErrNoEvidenceOfSpeedup is returned by CheckEvidence if no requested threshold received any support.
+	ErrNoEvidenceOfSpeedup = errors.New("no evidence of speedup at any requested threshold")
+)
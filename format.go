@@ -0,0 +1,125 @@
+package rtcompare
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ANSI escape sequences used by FormatResultsColor.
+const (
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiRed    = "\x1b[31m"
+	ansiReset  = "\x1b[0m"
+)
+
+// FormatResults renders results as a human-readable summary with one line per threshold, e.g.
+//
+//	Speedup ≥ 10.00% → Confidence: 97.300%
+//
+// The output contains no escape codes and is suitable for log files. Use FormatResultsColor for
+// colorized terminal output.
+func FormatResults(results []RTcomparisonResult) string {
+	return FormatResultsColor(results, false)
+}
+
+// FormatResultsColor renders results like FormatResults. If color is true, each confidence is wrapped
+// in ANSI color codes: green for a confidence ≥ 0.95, yellow for ≥ 0.5 and red below. NaN confidences
+// are never colored. Pass color = true only when writing to a terminal, so that output piped to a file
+// stays clean.
+func FormatResultsColor(results []RTcomparisonResult, color bool) string {
+	var sb strings.Builder
+	for _, r := range results {
+		confidence := fmt.Sprintf("%.3f%%", r.Confidence*100.0)
+		if color {
+			confidence = colorizeConfidence(confidence, r.Confidence)
+		}
+		fmt.Fprintf(&sb, "Speedup ≥ %.2f%% → Confidence: %s\n", r.RelativeSpeedupSampleAvsSampleB*100.0, confidence)
+	}
+	return sb.String()
+}
+
+// String returns the threshold and confidence of r in a compact form for logs, e.g.
+//
+//	speedup>=18.0% : conf=99.2%
+//
+// Both are percentages with one decimal, as in the output of FormatResults. This makes fmt.Println(results)
+// readable; ParseResult reads the line back. The other fields of r are not included.
+func (r RTcomparisonResult) String() string {
+	return fmt.Sprintf("speedup>=%.1f%% : conf=%.1f%%", r.RelativeSpeedupSampleAvsSampleB*100, r.Confidence*100)
+}
+
+// ParseResult parses a line produced by RTcomparisonResult.String and returns a result with
+// RelativeSpeedupSampleAvsSampleB and Confidence set; the other fields are zero. The values are those of the
+// line, i.e. rounded to 0.1 percentage points, e.g. "speedup>=18.0% : conf=99.2%" yields 0.18 and 0.992.
+// Surrounding whitespace is ignored. An error wrapping ErrInvalidResultFormat is returned if s does not
+// have this form.
+func ParseResult(s string) (RTcomparisonResult, error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(s), "speedup>=")
+	speedup, conf, found := strings.Cut(rest, " : conf=")
+	if !ok || !found {
+		return RTcomparisonResult{}, fmt.Errorf("%w: %q", ErrInvalidResultFormat, s)
+	}
+	var values [2]float64
+	for i, field := range []string{speedup, conf} {
+		number, ok := strings.CutSuffix(field, "%")
+		v, err := strconv.ParseFloat(number, 64)
+		if !ok || err != nil {
+			return RTcomparisonResult{}, fmt.Errorf("%w: %q is not a percentage in %q", ErrInvalidResultFormat, field, s)
+		}
+		values[i] = v / 100
+	}
+	return RTcomparisonResult{RelativeSpeedupSampleAvsSampleB: values[0], Confidence: values[1]}, nil
+}
+
+// colorizeConfidence wraps s in the ANSI color matching confidence. s is returned unchanged for NaN.
+func colorizeConfidence(s string, confidence float64) string {
+	var code string
+	switch {
+	case math.IsNaN(confidence):
+		return s
+	case confidence >= 0.95:
+		code = ansiGreen
+	case confidence >= 0.5:
+		code = ansiYellow
+	default:
+		code = ansiRed
+	}
+	return code + s + ansiReset
+}
+
+// Summarize compares A and B with CompareSamplesWithOptions (threshold 0, DefaultResamples, StrictGreater) and
+// returns a one-line summary for CI logs, e.g.
+//
+//	A is 18.3% faster than B (confidence 99.2%, n=120/120)
+//
+// The percentage is the observed speedup 1 - median(A)/median(B), the confidence is that of threshold 0 with
+// StrictGreater, i.e. the estimated probability that A is strictly faster; replicates with equal medians do
+// not count as faster. Such a line is returned if A is observed to be faster and the confidence is at least
+// 0.5; judge it by the stated confidence. The rule is symmetric: if A is observed to be slower and the strict
+// confidence that B is faster (computed by comparing B with A) is at least 0.5, Summarize returns e.g.
+// "A is 12.0% slower than B (confidence 98.1%, n=120/120)", where the percentage is median(A)/median(B) - 1.
+// Otherwise, e.g. for identical inputs, it returns "no significant difference". If the comparison fails,
+// e.g. because of too few measurements, the returned line starts with "cannot compare: " followed by the error.
+func Summarize(A, B []float64) string {
+	opts := CompareOptions{StrictGreater: true}
+	faster, err := CompareSamplesWithOptions(A, B, []float64{0}, opts)
+	if err != nil {
+		return "cannot compare: " + err.Error()
+	}
+	r := faster[0]
+	n := fmt.Sprintf("n=%d/%d", len(A), len(B))
+	if r.ObservedDelta > 0 && r.Confidence >= 0.5 {
+		return fmt.Sprintf("A is %.1f%% faster than B (confidence %.1f%%, %s)", r.ObservedDelta*100, r.Confidence*100, n)
+	}
+	if r.ObservedDelta < 0 {
+		slower, err := CompareSamplesWithOptions(B, A, []float64{0}, opts)
+		if err == nil && slower[0].Confidence >= 0.5 {
+			// ObservedDelta = 1 - medA/medB, so medA/medB - 1 = -ObservedDelta
+			return fmt.Sprintf("A is %.1f%% slower than B (confidence %.1f%%, %s)", -r.ObservedDelta*100, slower[0].Confidence*100, n)
+		}
+	}
+	return "no significant difference"
+}
@@ -0,0 +1,127 @@
+package rtcompare
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatResults(t *testing.T) {
+	results := []RTcomparisonResult{
+		{RelativeSpeedupSampleAvsSampleB: 0.1, Confidence: 0.973},
+		{RelativeSpeedupSampleAvsSampleB: 0.5, Confidence: 0.2},
+	}
+	want := "Speedup ≥ 10.00% → Confidence: 97.300%\nSpeedup ≥ 50.00% → Confidence: 20.000%\n"
+	assert.Equal(t, want, FormatResults(results))
+	assert.Equal(t, want, FormatResultsColor(results, false))
+	assert.Equal(t, "", FormatResults(nil))
+}
+
+func TestFormatResultsColor(t *testing.T) {
+	results := []RTcomparisonResult{
+		{RelativeSpeedupSampleAvsSampleB: 0.1, Confidence: 0.95},
+		{RelativeSpeedupSampleAvsSampleB: 0.2, Confidence: 0.5},
+		{RelativeSpeedupSampleAvsSampleB: 0.3, Confidence: 0.49},
+		{RelativeSpeedupSampleAvsSampleB: 0.4, Confidence: math.NaN()},
+	}
+	lines := strings.Split(strings.TrimSuffix(FormatResultsColor(results, true), "\n"), "\n")
+	assert.Len(t, lines, 4)
+	assert.Equal(t, "Speedup ≥ 10.00% → Confidence: \x1b[32m95.000%\x1b[0m", lines[0])
+	assert.Contains(t, lines[1], ansiYellow+"50.000%"+ansiReset)
+	assert.Contains(t, lines[2], ansiRed+"49.000%"+ansiReset)
+	assert.NotContains(t, lines[3], "\x1b[")
+}
+
+func TestSummarize(t *testing.T) {
+	rng := NewDPRNG(8)
+	fast := SyntheticSamples(60, 80, 0, 2, &rng)
+	slow := SyntheticSamples(40, 100, 0, 2, &rng)
+
+	s := Summarize(fast, slow)
+	assert.Regexp(t, `^A is \d+\.\d% faster than B \(confidence 100\.0%, n=60/40\)$`, s)
+	assert.Contains(t, s, fmt.Sprintf("%.1f%%", (1-Median(fast)/Median(slow))*100))
+
+	s = Summarize(slow, fast)
+	assert.Regexp(t, `^A is \d+\.\d% slower than B \(confidence 100\.0%, n=40/60\)$`, s)
+	assert.Contains(t, s, fmt.Sprintf("%.1f%%", (Median(slow)/Median(fast)-1)*100))
+
+	// B slightly faster, but not clearly: the confidence for "A faster" is well below 0.5 and well above 0.05,
+	// so A is reported as slower with the same bar of 0.5 that applies to faster
+	A := SyntheticSamples(30, 100, 0.03, 10, &rng)
+	B := SyntheticSamples(30, 100, 0, 10, &rng)
+	seeded, _ := CompareSamplesWithOptions(A, B, nil, CompareOptions{Seed: 1})
+	if assert.True(t, seeded[0].Confidence > 0.15 && seeded[0].Confidence < 0.4, "scenario confidence %v", seeded[0].Confidence) {
+		assert.Regexp(t, `^A is \d+\.\d% slower than B \(confidence [5-9]\d\.\d%, n=30/30\)$`, Summarize(A, B))
+	}
+
+	assert.True(t, strings.HasPrefix(Summarize(fast[:3], slow), "cannot compare: "))
+
+	// swapping A and B flips the verdict, also for a moderate confidence
+	rng = NewDPRNG(9)
+	A = SyntheticSamples(15, 92, 0, 15, &rng)
+	B = SyntheticSamples(15, 100, 0, 15, &rng)
+	seeded, _ = CompareSamplesWithOptions(A, B, nil, CompareOptions{StrictGreater: true, Seed: 1})
+	if assert.True(t, seeded[0].Confidence > 0.6 && seeded[0].Confidence < 0.9, "scenario confidence %v", seeded[0].Confidence) {
+		assert.Regexp(t, `^A is \d+\.\d% faster than B \(confidence \d+\.\d%, n=15/15\)$`, Summarize(A, B))
+		assert.Regexp(t, `^A is \d+\.\d% slower than B \(confidence \d+\.\d%, n=15/15\)$`, Summarize(B, A))
+	}
+
+	// identical inputs: equal medians must not count as "A is faster"
+	constant := slices.Repeat([]float64{100}, 20)
+	assert.Equal(t, "no significant difference", Summarize(constant, constant))
+	assert.Equal(t, "no significant difference", Summarize(fast, slices.Clone(fast)))
+	quantized := make([]float64, 51)
+	for i := range quantized {
+		quantized[i] = float64(100 + rng.UInt32N(3))
+	}
+	assert.Equal(t, "no significant difference", Summarize(quantized, slices.Clone(quantized)))
+}
+
+func TestResultString(t *testing.T) {
+	r := RTcomparisonResult{RelativeSpeedupSampleAvsSampleB: 0.18, Confidence: 0.992, Estimator: EstimatorMedian}
+	assert.Equal(t, "speedup>=18.0% : conf=99.2%", r.String())
+	assert.Equal(t, "[speedup>=18.0% : conf=99.2% speedup>=-5.0% : conf=100.0%]",
+		fmt.Sprint([]RTcomparisonResult{r, {RelativeSpeedupSampleAvsSampleB: -0.05, Confidence: 1}}))
+	assert.Equal(t, "speedup>=0.0% : conf=NaN%", RTcomparisonResult{Confidence: math.NaN()}.String())
+}
+
+func TestParseResult(t *testing.T) {
+	for _, r := range []RTcomparisonResult{
+		{RelativeSpeedupSampleAvsSampleB: 0.18, Confidence: 0.992},
+		{RelativeSpeedupSampleAvsSampleB: -0.05, Confidence: 1},
+		{RelativeSpeedupSampleAvsSampleB: 0, Confidence: 0},
+		{RelativeSpeedupSampleAvsSampleB: 0.12345, Confidence: 0.5},
+	} {
+		parsed, err := ParseResult(r.String())
+		if !assert.NoError(t, err) {
+			continue
+		}
+		assert.InDelta(t, r.RelativeSpeedupSampleAvsSampleB, parsed.RelativeSpeedupSampleAvsSampleB, 0.0005)
+		assert.InDelta(t, r.Confidence, parsed.Confidence, 0.0005)
+		assert.Equal(t, r.String(), parsed.String(), "String and ParseResult must round-trip")
+	}
+
+	parsed, err := ParseResult("  speedup>=18.0% : conf=99.2%\n")
+	assert.NoError(t, err)
+	assert.Equal(t, RTcomparisonResult{RelativeSpeedupSampleAvsSampleB: 0.18, Confidence: 0.992}, parsed)
+
+	parsed, err = ParseResult("speedup>=0.0% : conf=NaN%")
+	assert.NoError(t, err)
+	assert.True(t, math.IsNaN(parsed.Confidence))
+
+	for _, s := range []string{
+		"",
+		"speedup>=18.0%",
+		"speedup>=18.0% conf=99.2%",
+		"speedup>=18.0 : conf=99.2%",
+		"speedup>=x% : conf=99.2%",
+		"Speedup ≥ 10.00% → Confidence: 97.300%",
+	} {
+		_, err := ParseResult(s)
+		assert.ErrorIs(t, err, ErrInvalidResultFormat, "%q", s)
+	}
+}
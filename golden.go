@@ -0,0 +1,84 @@
+package rtcompare
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+)
+
+// GoldenA and GoldenB are a fixed dataset of 21 runtime measurements (in nanoseconds) each, used by
+// GoldenCompare. A is about 10% faster than B; both contain a few slow outliers, as real measurements do.
+// Do not modify them: they are package-level variables only so that their values can be inspected and
+// passed to other functions of this package to reproduce the documented results.
+var (
+	GoldenA = []float64{
+		912, 905, 921, 898, 934, 909, 915, 1102, 901, 927, 918,
+		896, 911, 940, 907, 923, 1250, 903, 916, 929, 910,
+	}
+	GoldenB = []float64{
+		1011, 1024, 998, 1035, 1007, 1019, 1290, 1002, 1028, 1013, 1041,
+		996, 1022, 1009, 1031, 1004, 1017, 1385, 1026, 1012, 1020,
+	}
+)
+
+// GoldenThresholds are the relative speedup thresholds evaluated by GoldenCompare.
+var GoldenThresholds = []float64{0, 0.05, 0.1, 0.15}
+
+// Parameters of GoldenCompare.
+const (
+	GoldenSeed      uint64 = 20240601
+	GoldenResamples uint64 = 10_000
+)
+
+// GoldenCompare runs CompareSamples on GoldenA and GoldenB for GoldenThresholds with GoldenResamples resamples
+// and the given seed (see CompareOptions.Seed). It is a living example and a regression canary: for
+// GoldenCompare(GoldenSeed) the confidences are exactly
+//
+//	threshold 0.00: 1.0000
+//	threshold 0.05: 1.0000
+//	threshold 0.10: 0.6171
+//	threshold 0.15: 0.0000
+//
+// with an observed speedup of 1 - 915/1019 ≈ 10.2%. Any change of the bootstrap, the median or the DPRNG
+// that alters these numbers changes the results of all seeded comparisons and should be a deliberate one.
+// A seed of zero uses a CPRNG, so the result is then not reproducible.
+func GoldenCompare(seed uint64) []RTcomparisonResult {
+	opts := defaultCompareOptions(GoldenResamples)
+	opts.Seed = seed
+	thresholds := append([]float64(nil), GoldenThresholds...)
+	result, err := compareSamples(GoldenA, GoldenB, thresholds, opts)
+	if err != nil {
+		panic(err) // the golden dataset is always large enough
+	}
+	return result
+}
+
+// FingerprintComparison returns a stable fingerprint of the seeded comparison of A and B: the hex-encoded
+// SHA-256 hash over the thresholds, the confidences CompareSamplesWithOptions reports for them with the given
+// resamples and seed, and the medians of A and B. Pin the fingerprint of representative data in your own
+// tests to detect a change of the statistical behavior, e.g. after upgrading this package:
+//
+//	if got := rtcompare.FingerprintComparison(A, B, thresholds, 10_000, 42); got != pinned {
+//		t.Errorf("comparison results changed: fingerprint %s", got)
+//	}
+//
+// Confidences are rounded to 9 decimal places before hashing, so that the fingerprint does not depend on
+// the last bits of floating-point arithmetic. The order of relativeGains does not matter, and relativeGains
+// is not modified. Returns an empty string if the comparison fails (see CompareSamples) or seed is zero,
+// which would select a CPRNG and make the results irreproducible.
+func FingerprintComparison(A, B []float64, relativeGains []float64, resamples, seed uint64) string {
+	if seed == 0 {
+		return ""
+	}
+	results, err := CompareSamplesWithOptions(A, B, slices.Clone(relativeGains), CompareOptions{Resamples: resamples, Seed: seed})
+	if err != nil {
+		return ""
+	}
+	h := sha256.New()
+	for _, r := range results {
+		fmt.Fprintf(h, "%g:%.9f;", r.RelativeSpeedupSampleAvsSampleB, r.Confidence)
+	}
+	fmt.Fprintf(h, "medians:%g/%g", Median(A), Median(B))
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,53 @@
+package rtcompare
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoldenCompare(t *testing.T) {
+	origA, origB, origT := slices.Clone(GoldenA), slices.Clone(GoldenB), slices.Clone(GoldenThresholds)
+	results := GoldenCompare(GoldenSeed)
+	assert.Equal(t, origA, GoldenA, "golden data must not be modified")
+	assert.Equal(t, origB, GoldenB, "golden data must not be modified")
+	assert.Equal(t, origT, GoldenThresholds, "golden thresholds must not be modified")
+
+	// these are the numbers documented for GoldenCompare; a change here changes all seeded comparisons
+	want := []float64{1, 1, 0.6171, 0}
+	if assert.Len(t, results, len(want)) {
+		for i, r := range results {
+			assert.Equal(t, GoldenThresholds[i], r.RelativeSpeedupSampleAvsSampleB)
+			assert.Equal(t, want[i], r.Confidence, "threshold %v", r.RelativeSpeedupSampleAvsSampleB)
+			assert.InDelta(t, 1-915.0/1019, r.ObservedDelta, 1e-15)
+		}
+	}
+	assert.Equal(t, results, GoldenCompare(GoldenSeed))
+	assert.NotEqual(t, results[2].Confidence, GoldenCompare(GoldenSeed + 1)[2].Confidence)
+
+	random := GoldenCompare(0)
+	assert.InDelta(t, want[2], random[2].Confidence, 0.05)
+	assert.False(t, math.IsNaN(random[0].Confidence))
+}
+
+func TestFingerprintComparison(t *testing.T) {
+	// pinned like a user would pin it; a change means that seeded comparisons behave differently
+	const pinned = "f51bdd59bfa3ce9efc1e3d33932c4472446d5fc68c93721ee620fd5bdd10f1c4"
+	thresholds := slices.Clone(GoldenThresholds)
+	fp := FingerprintComparison(GoldenA, GoldenB, thresholds, GoldenResamples, GoldenSeed)
+	assert.Equal(t, pinned, fp)
+	assert.Equal(t, GoldenThresholds, thresholds, "thresholds must not be modified")
+
+	reversed := slices.Clone(GoldenThresholds)
+	slices.Reverse(reversed)
+	assert.Equal(t, fp, FingerprintComparison(GoldenA, GoldenB, reversed, GoldenResamples, GoldenSeed))
+
+	assert.NotEqual(t, fp, FingerprintComparison(GoldenA, GoldenB, GoldenThresholds, GoldenResamples, GoldenSeed+1))
+	assert.NotEqual(t, fp, FingerprintComparison(GoldenA, GoldenB, GoldenThresholds[:3], GoldenResamples, GoldenSeed))
+	assert.NotEqual(t, fp, FingerprintComparison(GoldenB, GoldenA, GoldenThresholds, GoldenResamples, GoldenSeed))
+
+	assert.Empty(t, FingerprintComparison(GoldenA, GoldenB, GoldenThresholds, GoldenResamples, 0))
+	assert.Empty(t, FingerprintComparison(GoldenA[:5], GoldenB, GoldenThresholds, GoldenResamples, GoldenSeed))
+}
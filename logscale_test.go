@@ -0,0 +1,57 @@
+package rtcompare
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareSamplesLogMatchesLinear(t *testing.T) {
+	rng := NewDPRNG(13)
+	// right-skewed timings with multiplicative noise
+	A := make([]float64, 41)
+	B := make([]float64, 41)
+	for i := range A {
+		A[i] = 90 * math.Exp(0.2*NormalQuantile(rng.Float64OpenOpen()))
+		B[i] = 100 * math.Exp(0.2*NormalQuantile(rng.Float64OpenOpen()))
+	}
+	gains := []float64{0.2, -0.1, 0, 0.1, 1, 1.5}
+	origA, origGains := slices.Clone(A), slices.Clone(gains)
+
+	logResults, err := CompareSamplesLog(A, B, gains, 2000, 99)
+	assert.NoError(t, err)
+	assert.Equal(t, origA, A, "inputs must not be modified")
+	assert.Equal(t, origGains, gains, "gains must not be modified")
+	linResults, err := CompareSamplesWithOptions(A, B, gains, CompareOptions{Resamples: 2000, Seed: 99})
+	assert.NoError(t, err)
+	if assert.Len(t, logResults, len(linResults)) {
+		for i, lin := range linResults {
+			r := logResults[i]
+			assert.Equal(t, lin.RelativeSpeedupSampleAvsSampleB, r.RelativeSpeedupSampleAvsSampleB)
+			assert.InDelta(t, lin.Confidence, r.Confidence, 0.001, "threshold %v", lin.RelativeSpeedupSampleAvsSampleB)
+			assert.InDelta(t, lin.ObservedDelta, r.ObservedDelta, 1e-12)
+		}
+	}
+	assert.Equal(t, 0.0, logResults[4].Confidence, "a speedup of 100% is impossible")
+	assert.Equal(t, 0.0, logResults[5].Confidence)
+}
+
+func TestCompareSamplesLogErrors(t *testing.T) {
+	valid := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}
+	for _, bad := range []float64{0, -1, math.NaN()} {
+		invalid := slices.Clone(valid)
+		invalid[3] = bad
+		_, err := CompareSamplesLog(valid, invalid, nil, 100, 1)
+		assert.ErrorIs(t, err, ErrNonPositiveValues, "value %v", bad)
+		_, err = CompareSamplesLog(invalid, valid, nil, 100, 1)
+		assert.ErrorIs(t, err, ErrNonPositiveValues, "value %v", bad)
+	}
+	_, err := CompareSamplesLog(valid[:5], valid, nil, 100, 1)
+	assert.ErrorIs(t, err, ErrTooFewDataPoints)
+	results, err := CompareSamplesLog(valid, valid, nil, 100, 1)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, 0.0, results[0].RelativeSpeedupSampleAvsSampleB)
+}
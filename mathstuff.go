@@ -1,6 +1,7 @@
 package rtcompare
 
 import (
+	"fmt"
 	"math"
 	"slices"
 )
@@ -23,6 +24,52 @@ func Median(data []float64) float64 {
 	return dataCopy[l/2]
 }
 
+// MedianSorted returns the median of data, which must already be sorted in ascending order.
+// It returns the same value as Median (the upper middle element for an even length, 0.0 for an
+// empty slice), but skips the copy and the O(n log n) sort. Time complexity: O(1).
+// The precondition is not checked; passing unsorted data yields an arbitrary element.
+func MedianSorted(sorted []float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[len(sorted)/2]
+}
+
+// Percentile returns the p-th percentile (p in [0, 100]) of data: the element at index
+// min(floor(p/100 * n), n-1) of the sorted data, without interpolation. Consequently,
+// Percentile(data, 50) equals Median(data) (the upper median for even n), Percentile(data, 0)
+// returns the minimum and Percentile(data, 100) the maximum. Note that this is not the
+// nearest-rank method, which uses the index ceil(p/100 * n) - 1: where p/100 * n is an integer,
+// the result is the next larger element, e.g. Percentile of 1..10 at p = 10 is 2 instead of 1.
+// The function makes a copy of the input and sorts the copy, so the original slice is not modified.
+// Returns math.NaN() for an empty slice or if p is outside [0, 100] or NaN.
+// Time complexity: O(n log n). Space complexity: O(n) due to the copy required for sorting.
+func Percentile(data []float64, p float64) float64 {
+	if len(data) == 0 {
+		return math.NaN()
+	}
+	dataCopy := make([]float64, len(data))
+	copy(dataCopy, data)
+	slices.Sort(dataCopy)
+	return PercentileSorted(dataCopy, p)
+}
+
+// PercentileSorted returns the same value as Percentile for data that is already sorted in
+// ascending order, skipping the copy and the O(n log n) sort. Time complexity: O(1).
+// The precondition is not checked; passing unsorted data yields an arbitrary element.
+// Returns math.NaN() for an empty slice or if p is outside [0, 100] or NaN.
+func PercentileSorted(sorted []float64, p float64) float64 {
+	n := len(sorted)
+	if n == 0 || !(p >= 0 && p <= 100) {
+		return math.NaN()
+	}
+	idx := int(p / 100 * float64(n))
+	if idx >= n {
+		idx = n - 1
+	}
+	return sorted[idx]
+}
+
 // Statistics computes the arithmetic mean, population variance, and standard deviation
 // of the provided slice of float64 values.
 //
@@ -31,6 +78,11 @@ func Median(data []float64) float64 {
 // The variance returned is the population variance (sum of squared deviations divided by n).
 // The standard deviation is the square root of that variance.
 //
+// Both passes use Neumaier's compensated summation, so long slices of large values (e.g. byte counts
+// in the billions) and values of very different magnitude do not lose precision. The second pass sums
+// the squared deviations from the mean and subtracts the (compensated) sum of the deviations themselves,
+// which cancels the rounding error of the mean (the corrected two-pass algorithm).
+//
 // If the input slice is empty, the function returns mean = 0 and variance = stddev = -1
 // to indicate that the values are undefined for an empty dataset.
 func Statistics(data []float64) (mean, variance, stddev float64) {
@@ -38,22 +90,82 @@ func Statistics(data []float64) (mean, variance, stddev float64) {
 		return 0, -1, -1
 	}
 
-	var sum float64
 	n := float64(len(data))
 
+	var sum neumaierSum
 	for _, value := range data {
-		sum += value
+		sum.add(value)
 	}
-	mean = sum / n
+	mean = sum.value() / n
 
+	var squares, deviations neumaierSum
 	for _, value := range data {
-		variance += (value - mean) * (value - mean)
+		d := value - mean
+		squares.add(d * d)
+		deviations.add(d)
+	}
+	dev := deviations.value()
+	variance = (squares.value() - dev*dev/n) / n
+	if variance < 0 {
+		variance = 0 // guard against rounding below zero for (almost) constant data
 	}
-	variance /= n
 	stddev = math.Sqrt(variance)
 	return
 }
 
+// Moments computes the arithmetic mean, population variance, skewness and excess kurtosis of data.
+// Mean and variance are those of Statistics. The skewness is m3/m2^1.5 and the excess kurtosis
+// m4/m2² - 3, where mk is the k-th central moment (sum of (x - mean)^k divided by n), so both are 0
+// for a normal distribution. A positive skewness indicates a long right tail, e.g. occasional slow
+// runs; a positive excess kurtosis indicates heavier tails than the normal distribution.
+//
+// For constant data (variance 0), skewness and excessKurtosis are 0. For an empty slice, the function
+// returns mean = 0, variance = -1 (as Statistics) and skewness = excessKurtosis = math.NaN().
+func Moments(data []float64) (mean, variance, skewness, excessKurtosis float64) {
+	mean, variance, _ = Statistics(data)
+	if len(data) == 0 {
+		return mean, variance, math.NaN(), math.NaN()
+	}
+	if variance == 0 {
+		return mean, variance, 0, 0
+	}
+	var cubes, fourths neumaierSum
+	for _, value := range data {
+		d := value - mean
+		d2 := d * d
+		cubes.add(d2 * d)
+		fourths.add(d2 * d2)
+	}
+	n := float64(len(data))
+	skewness = cubes.value() / n / (variance * math.Sqrt(variance))
+	excessKurtosis = fourths.value()/n/(variance*variance) - 3
+	return mean, variance, skewness, excessKurtosis
+}
+
+// neumaierSum accumulates a sum of float64 values with Neumaier's variant of Kahan summation
+// (see https://en.wikipedia.org/wiki/Kahan_summation_algorithm#Further_enhancements). The
+// zero value is an empty sum.
+type neumaierSum struct {
+	sum          float64
+	compensation float64 // running total of the low-order bits lost in sum
+}
+
+// add adds x to the sum.
+func (s *neumaierSum) add(x float64) {
+	t := s.sum + x
+	if math.Abs(s.sum) >= math.Abs(x) {
+		s.compensation += (s.sum - t) + x
+	} else {
+		s.compensation += (x - t) + s.sum
+	}
+	s.sum = t
+}
+
+// value returns the compensated sum.
+func (s *neumaierSum) value() float64 {
+	return s.sum + s.compensation
+}
+
 // FloatsEqualWithTolerance reports whether f1 and f2 are approximately equal,
 // using a percentage-based absolute tolerance computed from each operand.
 //
@@ -90,18 +202,33 @@ func FloatsEqualWithTolerance(f1, f2, tolerancePercentage float64) bool {
 	return false
 }
 
-// Partition rearranges xs around a pivot and returns its final index
-func partition(xs []float64, low, high uint64) uint64 {
-	pivot := xs[high]
-	i := low
-	for j := low; j < high; j++ {
-		if xs[j] < pivot {
-			xs[i], xs[j] = xs[j], xs[i]
-			i++
-		}
+// number is the set of element types supported by the selection and resampling internals.
+type number interface {
+	~int64 | ~float64
+}
+
+// FloatsClose reports whether a and b are close to each other using the standard combined
+// relative/absolute tolerance rule (as in Python's math.isclose):
+//
+//	|a - b| <= max(relTol * max(|a|, |b|), absTol)
+//
+// Unlike FloatsEqualWithTolerance, relTol is a fraction (e.g. 1e-9 or 0.001 for 0.1%), not a
+// percentage, and absTol provides a floor for the tolerance, so values near zero, whose relative
+// tolerance collapses to ~0, can still compare as close. Use absTol = 0 for a purely relative and
+// relTol = 0 for a purely absolute comparison. Negative tolerances are treated as zero.
+//
+// Equal values (including equal infinities) are always close; NaN is never close to anything,
+// and an infinite value is never close to a finite one.
+func FloatsClose(a, b, relTol, absTol float64) bool {
+	if a == b {
+		return true
+	}
+	if math.IsInf(a, 0) || math.IsInf(b, 0) {
+		return false
 	}
-	xs[i], xs[high] = xs[high], xs[i]
-	return i
+	diff := math.Abs(a - b)
+	tol := math.Max(math.Max(relTol, 0)*math.Max(math.Abs(a), math.Abs(b)), math.Max(absTol, 0))
+	return diff <= tol
 }
 
 // quickselect finds the k-th smallest element (0-based index) in expected O(n) time.
@@ -116,33 +243,376 @@ func quickselect(xs []float64, k uint64) float64 {
 	if k >= uint64(len(xs)) {
 		return math.NaN()
 	}
+	return selectKth(xs, k)
+}
+
+// partition3 partitions xs[low..high] (low < high) three-way around the pivot value xs[low] and returns
+// lt <= gt such that xs[low..lt-1] < pivot, xs[lt..gt] == pivot and xs[gt+1..high] > pivot. Grouping the
+// elements equal to the pivot lets selectKth finish as soon as k falls into that group, so runs of
+// duplicates, e.g. timings quantized by a coarse timer such as the 100 ns QPC ticks on Windows, are
+// resolved in one pass instead of being partitioned again and again.
+//
+// It uses the Bentley-McIlroy scheme: Hoare-style scans from both ends that park elements equal to the
+// pivot at the ends of the range and swap them into the middle at the end. Compared to Dijkstra's "Dutch
+// national flag" partition, it needs fewer swaps for distinct values. For distinct values, the additional
+// equality checks do not pay off compared to Hoare's two-way scheme; BenchmarkSelectKthDuplicates compares
+// both for quantized and distinct inputs.
+func partition3[T number](xs []T, low, high int) (lt, gt int) {
+	pivot := xs[low]
+	// invariant: xs[low..a-1] == pivot, xs[a..b-1] < pivot, xs[c+1..d] > pivot, xs[d+1..high] == pivot
+	a, b, c, d := low+1, low+1, high, high
+	for {
+		for ; b <= c && xs[b] <= pivot; b++ {
+			if xs[b] == pivot {
+				xs[a], xs[b] = xs[b], xs[a]
+				a++
+			}
+		}
+		for ; c >= b && xs[c] >= pivot; c-- {
+			if xs[c] == pivot {
+				xs[c], xs[d] = xs[d], xs[c]
+				d--
+			}
+		}
+		if b > c {
+			break
+		}
+		xs[b], xs[c] = xs[c], xs[b]
+		b++
+		c--
+	}
+	// swap the equal elements from both ends next to the boundary b between smaller and larger elements
+	for s, i := min(a-low, b-a), 0; i < s; i++ {
+		xs[low+i], xs[b-s+i] = xs[b-s+i], xs[low+i]
+	}
+	for s, i := min(d-c, high-d), 0; i < s; i++ {
+		xs[b+i], xs[high-s+1+i] = xs[high-s+1+i], xs[b+i]
+	}
+	return low + (b - a), high - (d - c)
+}
+
+// selectKth is the generic implementation of quickselect, using partition3 with a random pivot.
+// xs must be non-empty and k < len(xs); the result is unspecified if xs contains NaN.
+func selectKth[T number](xs []T, k uint64) T {
 	rng := NewDPRNG()
-	low, high := uint64(0), uint64(len(xs)-1)
-	for low <= high {
-		pivotIndex := rng.Uint64()%(high-low+1) + low
-		xs[pivotIndex], xs[high] = xs[high], xs[pivotIndex] // move pivot to end
-		p := partition(xs, low, high)
-		if p == k {
-			return xs[p]
-		} else if p < k {
-			low = p + 1
+	kk := int(k)
+	low, high := 0, len(xs)-1
+	for low < high {
+		pivotIndex := low + int(rng.Uint64()%uint64(high-low+1))
+		xs[pivotIndex], xs[low] = xs[low], xs[pivotIndex] // move pivot to start
+		lt, gt := partition3(xs, low, high)
+		if kk < lt {
+			high = lt - 1
+		} else if kk > gt {
+			low = gt + 1
 		} else {
-			high = p - 1
+			return xs[kk] // k is in the group equal to the pivot
+		}
+	}
+	return xs[kk]
+}
+
+// smallMedianCutoff is the largest slice length for which medianAsFloat uses insertionSortMedian
+// instead of selectKth. Below it, the lower constant factors of insertion sort (no PRNG setup, no
+// pivot swaps) outweigh its O(n²) comparisons. The cutoff stays safely below the break-even point
+// measured by BenchmarkMedianSmall.
+const smallMedianCutoff = 48
+
+// medianAsFloat returns the (upper) median of xs as float64 in expected O(n) time, or math.NaN()
+// for an empty slice or one containing a NaN. For []float64 it is equivalent to QuickMedian. xs is modified.
+// Lengths 3, 5, 7 and 9 are handled by sorting networks (see medianNetwork), other short slices by
+// insertion sort and longer ones by selectKth.
+func medianAsFloat[T number](xs []T) float64 {
+	if len(xs) == 0 || hasNaN(xs) {
+		return math.NaN()
+	}
+	if median, ok := medianNetwork(xs); ok {
+		return float64(median)
+	}
+	if len(xs) <= smallMedianCutoff {
+		return float64(insertionSortMedian(xs))
+	}
+	return float64(selectKth(xs, uint64(len(xs))/2))
+}
+
+// hasNaN reports whether xs contains a NaN. For integer types it is always false.
+func hasNaN[T number](xs []T) bool {
+	for _, x := range xs {
+		if x != x {
+			return true
+		}
+	}
+	return false
+}
+
+// insertionSortMedian sorts xs in place with insertion sort and returns its (upper) median element
+// xs[len(xs)/2]. xs must be non-empty. Intended for short slices only.
+func insertionSortMedian[T number](xs []T) T {
+	for i := 1; i < len(xs); i++ {
+		x := xs[i]
+		j := i
+		for ; j > 0 && xs[j-1] > x; j-- {
+			xs[j] = xs[j-1]
 		}
+		xs[j] = x
+	}
+	return xs[len(xs)/2]
+}
+
+// medianNetwork returns the median of xs computed with a sorting network if len(xs) is 3, 5, 7 or 9, and
+// ok = false for all other lengths. The networks are the minimal median networks of Paeth and Devillard
+// (see http://ndevilla.free.fr/median/median/index.html): a fixed sequence of compare-exchange steps
+// without recursion or allocation. For these lengths they are several times faster than quickselect
+// and faster than insertion sort (see BenchmarkMedianNetwork). xs is modified.
+func medianNetwork[T number](xs []T) (median T, ok bool) {
+	switch len(xs) {
+	case 3:
+		return median3(xs), true
+	case 5:
+		return median5(xs), true
+	case 7:
+		return median7(xs), true
+	case 9:
+		return median9(xs), true
+	}
+	return median, false
+}
+
+// cswap orders xs[i] and xs[j] so that xs[i] <= xs[j].
+func cswap[T number](xs []T, i, j int) {
+	if xs[i] > xs[j] {
+		xs[i], xs[j] = xs[j], xs[i]
 	}
-	return xs[k] // fallback
+}
+
+// median3 returns the median of the 3 elements of xs. xs is modified.
+func median3[T number](xs []T) T {
+	_ = xs[2]
+	cswap(xs, 0, 1)
+	cswap(xs, 1, 2)
+	cswap(xs, 0, 1)
+	return xs[1]
+}
+
+// median5 returns the median of the 5 elements of xs. xs is modified.
+func median5[T number](xs []T) T {
+	_ = xs[4]
+	cswap(xs, 0, 1)
+	cswap(xs, 3, 4)
+	cswap(xs, 0, 3)
+	cswap(xs, 1, 4)
+	cswap(xs, 1, 2)
+	cswap(xs, 2, 3)
+	cswap(xs, 1, 2)
+	return xs[2]
+}
+
+// median7 returns the median of the 7 elements of xs. xs is modified.
+func median7[T number](xs []T) T {
+	_ = xs[6]
+	cswap(xs, 0, 5)
+	cswap(xs, 0, 3)
+	cswap(xs, 1, 6)
+	cswap(xs, 2, 4)
+	cswap(xs, 0, 1)
+	cswap(xs, 3, 5)
+	cswap(xs, 2, 6)
+	cswap(xs, 2, 3)
+	cswap(xs, 3, 6)
+	cswap(xs, 4, 5)
+	cswap(xs, 1, 4)
+	cswap(xs, 1, 3)
+	cswap(xs, 3, 4)
+	return xs[3]
+}
+
+// median9 returns the median of the 9 elements of xs. xs is modified.
+func median9[T number](xs []T) T {
+	_ = xs[8]
+	cswap(xs, 1, 2)
+	cswap(xs, 4, 5)
+	cswap(xs, 7, 8)
+	cswap(xs, 0, 1)
+	cswap(xs, 3, 4)
+	cswap(xs, 6, 7)
+	cswap(xs, 1, 2)
+	cswap(xs, 4, 5)
+	cswap(xs, 7, 8)
+	cswap(xs, 0, 3)
+	cswap(xs, 5, 8)
+	cswap(xs, 4, 7)
+	cswap(xs, 3, 6)
+	cswap(xs, 1, 4)
+	cswap(xs, 2, 5)
+	cswap(xs, 4, 7)
+	cswap(xs, 4, 2)
+	cswap(xs, 6, 4)
+	cswap(xs, 4, 2)
+	return xs[4]
 }
 
 // QuickMedian returns the median in expected O(n) time.
 // In case of an odd number of elements, it returns the middle one.
-// In case of an even number of elements, it returns the higher of the two middle ones.
+// In case of an even number of elements, it returns the higher of the two middle ones
+// (use QuickMedianLower for the lower one).
 // Returns math.NaN() for an empty input slice.
 // Note: This function modifies the input array. To avoid this, pass a copy.
+//
+// Duplicates: the result is the value of the element at index len(xs)/2 of the sorted slice, i.e. it equals
+// Median(xs) and MedianSorted of the sorted slice. This holds for any number of duplicates and does not depend
+// on the (random) pivot choices; only the order in which xs is left may differ between calls.
+//
+// NaN: NaN is not ordered (every comparison with it is false), so it cannot be placed in the sorted order
+// and would corrupt the selection. QuickMedian therefore returns math.NaN() if xs contains a NaN, making a
+// stray NaN visible instead of silently yielding an arbitrary element. In a bootstrap, replicates that draw
+// a NaN thus yield a NaN median and do not meet any threshold (see BootstrapConfidence). Remove NaN values
+// first (see CleanSamples) to get the median of the remaining values. ±Inf values are ordered and handled
+// like any other value.
 func QuickMedian(xs []float64) float64 {
-	if len(xs) == 0 {
+	if len(xs) == 0 || hasNaN(xs) {
 		return math.NaN()
 	}
+	if median, ok := medianNetwork(xs); ok {
+		return median
+	}
 	n := uint64(len(xs))
 	median := quickselect(xs, n/2)
 	return median
 }
+
+// QuickMedianLower is like QuickMedian but returns the lower of the two middle elements for an even number
+// of elements, i.e. the element at index (len(xs)-1)/2 of the sorted slice. This is the conventional "lower
+// median" of many statistics packages. For an odd number of elements, it equals QuickMedian.
+// Returns math.NaN() for an empty input slice or if xs contains a NaN (see QuickMedian).
+// Note: This function modifies the input array. To avoid this, pass a copy.
+func QuickMedianLower(xs []float64) float64 {
+	if len(xs) == 0 || hasNaN(xs) {
+		return math.NaN()
+	}
+	return quickselect(xs, uint64(len(xs)-1)/2)
+}
+
+// Medians returns the median of each group, medians[i] being QuickMedian of groups[i], for workflows with
+// many small groups, e.g. summarizing thousands of sub-benchmarks. All groups are copied into one reused
+// scratch buffer, so apart from the result slice no memory is allocated per group, and short groups are
+// handled by the sorting networks and insertion sort of the bootstrap (see medianAsFloat) without the
+// setup costs of quickselect. The median of an empty group, or of a group containing NaN, is math.NaN().
+// The groups are not modified. Returns nil for a nil input.
+func Medians(groups [][]float64) []float64 {
+	if groups == nil {
+		return nil
+	}
+	longest := 0
+	for _, g := range groups {
+		longest = max(longest, len(g))
+	}
+	scratch := make([]float64, longest)
+	medians := make([]float64, len(groups))
+	for i, g := range groups {
+		buf := scratch[:len(g)]
+		copy(buf, g)
+		medians[i] = medianAsFloat(buf)
+	}
+	return medians
+}
+
+// normalizeConfidence converts a confidence level given either as a fraction in [0, 1] (e.g. 0.95) or as a
+// percentage in (1, 100] (e.g. 95) to a fraction. Values outside these ranges and NaN yield an error
+// wrapping ErrInvalidConfidence. Note that 1 is interpreted as the fraction 1 (i.e. 100%), not as 1%.
+// Callers check the normalized value against the range they actually support, e.g. (0, 1) for intervals.
+func normalizeConfidence(c float64) (float64, error) {
+	switch {
+	case c >= 0 && c <= 1:
+		return c, nil
+	case c > 1 && c <= 100:
+		return c / 100, nil
+	default:
+		return math.NaN(), fmt.Errorf("%w: %v is neither a fraction in [0, 1] nor a percentage in (1, 100]", ErrInvalidConfidence, c)
+	}
+}
+
+// MeanConfidenceInterval returns the two-sided confidence interval [lo, hi] for the mean of the
+// population data was drawn from, based on Student's t-distribution:
+//
+//	mean ± StudentTQuantile(1-(1-confidence)/2, n-1) * s / sqrt(n)
+//
+// where s is the sample standard deviation (with Bessel's correction, derived from the population
+// variance returned by Statistics). For example, confidence = 0.95 (or 95, see below) yields a 95% interval.
+//
+// This parametric interval assumes approximately normally distributed data (or a sample large enough
+// for the central limit theorem to apply). It is much cheaper than a bootstrap, but for skewed data
+// such as typical runtime measurements, prefer the median-based bootstrap of CompareSamples.
+//
+// Like all functions of this package taking a confidence level, it accepts the level either as a fraction
+// in [0, 1] or as a percentage in (1, 100], i.e. 0.95 and 95 are equivalent.
+// Returns math.NaN() for both bounds if data contains fewer than two values or confidence is not
+// in the open interval (0, 1) (or (0%, 100%)).
+func MeanConfidenceInterval(data []float64, confidence float64) (lo, hi float64) {
+	n := len(data)
+	confidence, err := normalizeConfidence(confidence)
+	if n < 2 || err != nil || !(confidence > 0 && confidence < 1) {
+		return math.NaN(), math.NaN()
+	}
+	mean, variance, _ := Statistics(data)
+	sampleStdDev := math.Sqrt(variance * float64(n) / float64(n-1))
+	t := StudentTQuantile(1-(1-confidence)/2, float64(n-1))
+	halfWidth := t * sampleStdDev / math.Sqrt(float64(n))
+	return mean - halfWidth, mean + halfWidth
+}
+
+// confidenceBoundZ is the standard normal quantile for the 95% Wilson score interval of the confidences,
+// NormalQuantile(0.975).
+const confidenceBoundZ = 1.959963984540054
+
+// wilsonLowerBound returns the lower bound of the two-sided 95% Wilson score interval for a proportion p
+// estimated from n Bernoulli trials, e.g. a bootstrap confidence from n resamples. Unlike the normal
+// approximation p - z·sqrt(p(1-p)/n), it stays within [0, 1] and is informative for p = 0 and p = 1.
+// Returns math.NaN() if p is NaN or n is zero.
+func wilsonLowerBound(p float64, n uint64) float64 {
+	if math.IsNaN(p) || n == 0 {
+		return math.NaN()
+	}
+	z2 := confidenceBoundZ * confidenceBoundZ
+	nf := float64(n)
+	center := p + z2/(2*nf)
+	margin := confidenceBoundZ * math.Sqrt(p*(1-p)/nf+z2/(4*nf*nf))
+	return max(0, (center-margin)/(1+z2/nf))
+}
+
+// Jackknife computes the leave-one-out jackknife of the statistic stat on samples. estimates[i] is
+// stat applied to samples without its i-th element. From these, with θ̂ = stat(samples) and θ̄ the mean
+// of the estimates, it derives
+//
+//	bias   = (n-1) * (θ̄ - θ̂)
+//	stderr = sqrt((n-1)/n * Σ (estimates[i] - θ̄)²)
+//
+// Besides being a building block for bias-corrected bootstrap intervals, the estimates are a useful
+// diagnostic on their own: an estimate far away from the others points to an influential outlier.
+// Note that the jackknife standard error is not consistent for non-smooth statistics such as the
+// median; use it as a sensitivity indicator rather than an exact error there.
+//
+// stat receives a reused buffer (which it may modify, e.g. QuickMedian) and must not retain it.
+// The input slice is not modified. Returns nil estimates and math.NaN() for bias and stderr if samples
+// contains fewer than two values.
+func Jackknife(samples []float64, stat func([]float64) float64) (estimates []float64, bias, stderr float64) {
+	n := len(samples)
+	if n < 2 {
+		return nil, math.NaN(), math.NaN()
+	}
+	buf := make([]float64, n)
+	copy(buf, samples)
+	full := stat(buf)
+
+	estimates = make([]float64, n)
+	subset := buf[:n-1]
+	for i := range samples {
+		copy(subset, samples[:i])
+		copy(subset[i:], samples[i+1:])
+		estimates[i] = stat(subset)
+	}
+
+	mean, variance, _ := Statistics(estimates)
+	bias = float64(n-1) * (mean - full)
+	stderr = math.Sqrt(float64(n-1) * variance) // variance is Σ(...)²/n
+	return estimates, bias, stderr
+}
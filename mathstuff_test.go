@@ -1,6 +1,7 @@
 package rtcompare
 
 import (
+	"fmt"
 	"math"
 	"math/rand"
 	"slices"
@@ -181,3 +182,632 @@ func TestQuickMedianEmpty(t *testing.T) {
 		t.Fatalf("expected NaN for empty input, got %v", got)
 	}
 }
+
+func TestMeanConfidenceInterval(t *testing.T) {
+	data := []float64{3, 53, 512, 11, 75, 201, 335} // mean 170, population variance 31576.285714285714
+	lo, hi := MeanConfidenceInterval(data, 0.95)
+	s := math.Sqrt(31576.285714285714 * 7 / 6)
+	halfWidth := 2.4469118511449692 * s / math.Sqrt(7) // t(0.975, 6)
+	assert.InDelta(t, 170-halfWidth, lo, 1e-6)
+	assert.InDelta(t, 170+halfWidth, hi, 1e-6)
+
+	lo99, hi99 := MeanConfidenceInterval(data, 0.99)
+	assert.True(t, lo99 < lo && hi99 > hi, "a 99%% interval must be wider than a 95%% interval")
+
+	lo, hi = MeanConfidenceInterval([]float64{5, 5, 5}, 0.95)
+	assert.Equal(t, 5.0, lo)
+	assert.Equal(t, 5.0, hi)
+
+	for _, tc := range []struct {
+		data       []float64
+		confidence float64
+	}{
+		{nil, 0.95},
+		{[]float64{1}, 0.95},
+		{[]float64{1, 2, 3}, 0},
+		{[]float64{1, 2, 3}, 1},
+		{[]float64{1, 2, 3}, math.NaN()},
+	} {
+		lo, hi := MeanConfidenceInterval(tc.data, tc.confidence)
+		assert.True(t, math.IsNaN(lo) && math.IsNaN(hi), "expected NaN bounds for data=%v confidence=%v", tc.data, tc.confidence)
+	}
+}
+
+func TestMedianSortedMatchesMedian(t *testing.T) {
+	rng := NewDPRNG(0x1234567890ABCDEF)
+	for n := range 50 {
+		data := make([]float64, n)
+		rng.FillFloat64(data)
+		sorted := slices.Clone(data)
+		slices.Sort(sorted)
+		assert.True(t, slices.IsSorted(sorted))
+		assert.Equal(t, Median(data), MedianSorted(sorted), "n=%d", n)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	data := []float64{7, 1, 9, 3, 5, 2, 8, 4, 6, 10} // sorted: 1..10
+	testCases := []struct {
+		p        float64
+		expected float64
+	}{
+		{0, 1},
+		{10, 2},
+		{25, 3},
+		{50, 6},
+		{90, 10},
+		{99, 10},
+		{100, 10},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, Percentile(data, tc.p), "p=%v", tc.p)
+	}
+	assert.Equal(t, []float64{7, 1, 9, 3, 5, 2, 8, 4, 6, 10}, data, "input must not be modified")
+	assert.Equal(t, Median(data), Percentile(data, 50))
+
+	for _, p := range []float64{-1, 100.1, math.NaN()} {
+		assert.True(t, math.IsNaN(Percentile(data, p)), "expected NaN for p=%v", p)
+	}
+	assert.True(t, math.IsNaN(Percentile(nil, 50)), "expected NaN for empty input")
+}
+
+func TestPercentileSortedMatchesPercentile(t *testing.T) {
+	rng := NewDPRNG(42)
+	for n := 1; n < 100; n += 7 {
+		data := make([]float64, n)
+		rng.FillFloat64(data)
+		sorted := slices.Clone(data)
+		slices.Sort(sorted)
+		assert.True(t, slices.IsSorted(sorted))
+		for p := 0.0; p <= 100; p += 2.5 {
+			assert.Equal(t, Percentile(data, p), PercentileSorted(sorted, p), "n=%d p=%v", n, p)
+		}
+	}
+}
+
+func TestFloatsClose(t *testing.T) {
+	testCases := []struct {
+		a, b, relTol, absTol float64
+		expected             bool
+	}{
+		{1.0, 1.0, 0, 0, true},                           // exact match
+		{1.0, 1.05, 0.1, 0, true},                        // within relative tolerance
+		{1.0, 1.15, 0.1, 0, false},                       // outside relative tolerance
+		{100, 110, 0.1, 0, true},                         // relative to the larger magnitude
+		{1e-12, 2e-12, 0.1, 0, false},                    // near zero: relative tolerance collapses
+		{1e-12, 2e-12, 0.1, 1e-9, true},                  // ... but the absolute floor helps
+		{0, 1e-10, 1e-9, 0, false},                       // zero is only close to zero relatively
+		{0, 1e-10, 1e-9, 1e-9, true},                     // absolute tolerance
+		{5, 7, 0, 2, true},                               // purely absolute
+		{5, 7.5, 0, 2, false},                            // purely absolute
+		{-1, 1, 0.5, 0, false},                           // opposite signs
+		{1, 1.1, -0.5, -1, false},                        // negative tolerances are treated as zero
+		{math.Inf(1), math.Inf(1), 0, 0, true},           // equal infinities
+		{math.Inf(1), math.Inf(-1), 1, 1, false},         // different infinities
+		{math.Inf(1), 1e308, 1, 1, false},                // infinity vs. finite
+		{math.NaN(), math.NaN(), 1, 1, false},            // NaN
+		{math.NaN(), 1, math.Inf(1), math.Inf(1), false}, // NaN with infinite tolerance
+	}
+
+	for _, tc := range testCases {
+		result := FloatsClose(tc.a, tc.b, tc.relTol, tc.absTol)
+		assert.True(t, result == tc.expected, "FloatsClose(%v, %v, %v, %v) should be %v", tc.a, tc.b, tc.relTol, tc.absTol, tc.expected)
+	}
+}
+
+func TestInsertionSortMedianMatchesSelectKth(t *testing.T) {
+	rng := NewDPRNG(42)
+	for n := 1; n <= 2*smallMedianCutoff; n++ {
+		for range 20 {
+			xs := make([]float64, n)
+			for i := range xs {
+				xs[i] = float64(rng.UInt32N(10)) // many duplicates
+			}
+			ys := slices.Clone(xs)
+			assert.Equal(t, selectKth(ys, uint64(n/2)), insertionSortMedian(xs), "n=%d", n)
+			assert.True(t, slices.IsSorted(xs))
+		}
+	}
+	ints := []int64{5, -3, 9, 1, 1}
+	assert.Equal(t, int64(1), insertionSortMedian(ints))
+	assert.True(t, math.IsNaN(medianAsFloat([]float64{})))
+}
+
+// BenchmarkMedianSmall compares insertionSortMedian with selectKth for short slices to determine smallMedianCutoff.
+func BenchmarkMedianSmall(b *testing.B) {
+	for _, n := range []int{8, 16, 24, 32, 48, 64, 128} {
+		rng := NewDPRNG(42)
+		src := make([]float64, n)
+		rng.FillFloat64(src)
+		xs := make([]float64, n)
+		b.Run(fmt.Sprintf("insertion/n=%d", n), func(b *testing.B) {
+			for b.Loop() {
+				copy(xs, src)
+				insertionSortMedian(xs)
+			}
+		})
+		b.Run(fmt.Sprintf("quickselect/n=%d", n), func(b *testing.B) {
+			for b.Loop() {
+				copy(xs, src)
+				selectKth(xs, uint64(n/2))
+			}
+		})
+	}
+}
+
+func TestMedianNetworks(t *testing.T) {
+	// Exhaustive check over all permutations of 0..n-1 (with duplicates mapped by i/2 to cover ties).
+	for _, n := range []int{3, 5, 7, 9} {
+		perm := make([]int64, n)
+		for i := range perm {
+			perm[i] = int64(i)
+		}
+		xs := make([]int64, n)
+		dup := make([]int64, n)
+		for {
+			copy(xs, perm)
+			m, ok := medianNetwork(xs)
+			assert.True(t, ok)
+			if m != int64(n/2) {
+				t.Fatalf("n=%d: median of %v = %d, want %d", n, perm, m, n/2)
+			}
+			for i, v := range perm {
+				dup[i] = v / 2
+			}
+			want := slices.Clone(dup)
+			slices.Sort(want)
+			if m, _ := medianNetwork(dup); m != want[n/2] {
+				t.Fatalf("n=%d: median with ties = %d, want %d", n, m, want[n/2])
+			}
+			if !nextPermutation(perm) {
+				break
+			}
+		}
+	}
+	for _, n := range []int{0, 1, 2, 4, 6, 8, 10} {
+		_, ok := medianNetwork(make([]float64, n))
+		assert.False(t, ok, "n=%d", n)
+	}
+	assert.Equal(t, 3.0, QuickMedian([]float64{5, 1, 4, 2, 3}))
+	assert.Equal(t, 3.0, medianAsFloat([]int64{5, 1, 4, 2, 3}))
+}
+
+// nextPermutation rearranges p into the lexicographically next permutation and reports whether one exists.
+func nextPermutation(p []int64) bool {
+	i := len(p) - 2
+	for i >= 0 && p[i] >= p[i+1] {
+		i--
+	}
+	if i < 0 {
+		return false
+	}
+	j := len(p) - 1
+	for p[j] <= p[i] {
+		j--
+	}
+	p[i], p[j] = p[j], p[i]
+	slices.Reverse(p[i+1:])
+	return true
+}
+
+func BenchmarkMedianNetwork(b *testing.B) {
+	for _, n := range []int{3, 5, 7, 9} {
+		rng := NewDPRNG(42)
+		src := make([]float64, n)
+		rng.FillFloat64(src)
+		xs := make([]float64, n)
+		b.Run(fmt.Sprintf("network/n=%d", n), func(b *testing.B) {
+			for b.Loop() {
+				copy(xs, src)
+				medianNetwork(xs)
+			}
+		})
+		b.Run(fmt.Sprintf("insertion/n=%d", n), func(b *testing.B) {
+			for b.Loop() {
+				copy(xs, src)
+				insertionSortMedian(xs)
+			}
+		})
+		b.Run(fmt.Sprintf("quickselect/n=%d", n), func(b *testing.B) {
+			for b.Loop() {
+				copy(xs, src)
+				quickselect(xs, uint64(n/2))
+			}
+		})
+	}
+}
+
+func TestJackknife(t *testing.T) {
+	data := []float64{3, 53, 512, 11, 75, 201, 335}
+	orig := slices.Clone(data)
+	mean := func(xs []float64) float64 { m, _, _ := Statistics(xs); return m }
+
+	// For the mean, the jackknife is unbiased and its standard error equals s/sqrt(n).
+	estimates, bias, stderr := Jackknife(data, mean)
+	assert.Equal(t, orig, data, "input must not be modified")
+	assert.Len(t, estimates, len(data))
+	assert.InDelta(t, (170*7-3)/6.0, estimates[0], 1e-9)
+	assert.InDelta(t, 0, bias, 1e-9)
+	s := math.Sqrt(31576.285714285714 * 7 / 6)
+	assert.InDelta(t, s/math.Sqrt(7), stderr, 1e-9)
+
+	// For the population variance, the jackknife bias equals -s²/n (the known bias of the plug-in estimator).
+	variance := func(xs []float64) float64 { _, v, _ := Statistics(xs); return v }
+	_, bias, _ = Jackknife(data, variance)
+	assert.InDelta(t, -s*s/7, bias, 1e-6)
+
+	// stat may modify its argument.
+	estimates, _, _ = Jackknife(data, QuickMedian)
+	assert.Equal(t, orig, data)
+	assert.Equal(t, []float64{201, 201, 75, 201, 201, 75, 75}, estimates)
+
+	for _, xs := range [][]float64{nil, {1}} {
+		estimates, bias, stderr = Jackknife(xs, mean)
+		assert.Nil(t, estimates)
+		assert.True(t, math.IsNaN(bias) && math.IsNaN(stderr))
+	}
+}
+
+func TestStatisticsLargeMagnitude(t *testing.T) {
+	// Naive summation loses every 1 added to 1e16 and ends up with a sum of 0 instead of 1000.
+	data := []float64{1e16}
+	for range 1000 {
+		data = append(data, 1)
+	}
+	data = append(data, -1e16)
+	var naive float64
+	for _, x := range data {
+		naive += x
+	}
+	assert.Equal(t, 0.0, naive, "naive summation should visibly drift for this dataset")
+	mean, _, _ := Statistics(data)
+	assert.Equal(t, 1000/1002.0, mean)
+
+	// Byte counts in the billions with a small spread: the variance must not be swamped by rounding.
+	rng := NewDPRNG(42)
+	large := make([]float64, 100_000)
+	small := make([]float64, len(large))
+	for i := range large {
+		small[i] = float64(rng.UInt32N(1000)) + 0.1
+		large[i] = 3e12 + small[i]
+	}
+	meanSmall, varSmall, _ := Statistics(small)
+	meanLarge, varLarge, _ := Statistics(large)
+	assert.InDelta(t, 3e12+meanSmall, meanLarge, 1e-3)
+	assert.InDelta(t, varSmall, varLarge, varSmall*1e-6)
+}
+
+func TestNormalizeConfidence(t *testing.T) {
+	for _, tc := range []struct{ in, want float64 }{
+		{0, 0}, {0.95, 0.95}, {1, 1}, {95, 0.95}, {99.9, 0.999}, {100, 1}, {1.5, 0.015},
+	} {
+		got, err := normalizeConfidence(tc.in)
+		assert.NoError(t, err)
+		assert.InDelta(t, tc.want, got, 1e-15, "normalizeConfidence(%v)", tc.in)
+	}
+	for _, c := range []float64{-0.1, 100.1, 950, math.NaN(), math.Inf(1)} {
+		got, err := normalizeConfidence(c)
+		assert.ErrorIs(t, err, ErrInvalidConfidence, "normalizeConfidence(%v)", c)
+		assert.True(t, math.IsNaN(got))
+	}
+}
+
+func TestConfidenceAsPercentage(t *testing.T) {
+	data := []float64{3, 53, 512, 11, 75, 201, 335}
+	lo, hi := MeanConfidenceInterval(data, 0.95)
+	loPct, hiPct := MeanConfidenceInterval(data, 95)
+	assert.Equal(t, lo, loPct)
+	assert.Equal(t, hi, hiPct)
+
+	A := []float64{1, 2, 3, 4, 5}
+	B := []float64{2, 4, 6, 8, 10}
+	p, lo, hi := SpeedupFactorCI(A, B, 0.9, 500, 1)
+	pPct, loPct, hiPct := SpeedupFactorCI(A, B, 90, 500, 1)
+	assert.Equal(t, []float64{p, lo, hi}, []float64{pPct, loPct, hiPct})
+
+	assert.Equal(t, RequiredSamples(data, 0.5, 0.9), RequiredSamples(data, 0.5, 90))
+	assert.Equal(t, Verdict([]RTcomparisonResult{{RelativeSpeedupSampleAvsSampleB: 0.1, Confidence: 0.99}}, 95), OutcomeFaster)
+}
+
+func TestQuickMedianDuplicateContract(t *testing.T) {
+	rng := NewDPRNG(0xD0B1E)
+	for n := 1; n <= 200; n++ {
+		for _, distinct := range []uint32{1, 2, 3, 1000} {
+			xs := make([]float64, n)
+			for i := range xs {
+				xs[i] = float64(rng.UInt32N(distinct))
+			}
+			sorted := slices.Clone(xs)
+			slices.Sort(sorted)
+			assert.Equal(t, sorted[n/2], QuickMedian(slices.Clone(xs)), "n=%d distinct=%d", n, distinct)
+			assert.Equal(t, sorted[(n-1)/2], QuickMedianLower(slices.Clone(xs)), "n=%d distinct=%d", n, distinct)
+			assert.Equal(t, Median(xs), QuickMedian(slices.Clone(xs)))
+		}
+	}
+}
+
+func TestQuickMedianLower(t *testing.T) {
+	assert.Equal(t, 2.0, QuickMedianLower([]float64{4, 1, 3, 2}))
+	assert.Equal(t, 3.0, QuickMedian([]float64{4, 1, 3, 2}))
+	assert.Equal(t, 3.0, QuickMedianLower([]float64{5, 1, 3, 2, 4}))
+	assert.Equal(t, 7.0, QuickMedianLower([]float64{7}))
+	assert.Equal(t, 5.0, QuickMedianLower([]float64{5, 5, 9, 9}))
+	assert.True(t, math.IsNaN(QuickMedianLower(nil)))
+}
+
+func TestQuickMedianNaN(t *testing.T) {
+	// lengths covering the sorting networks, insertion sort and quickselect
+	for _, n := range []int{1, 2, 3, 4, 5, 7, 9, 10, 47, 48, 49, 100, 1001} {
+		rng := NewDPRNG(uint64(n))
+		for _, pos := range []int{0, n / 2, n - 1} {
+			xs := make([]float64, n)
+			rng.FillFloat64(xs)
+			xs[pos] = math.NaN()
+			assert.True(t, math.IsNaN(QuickMedian(slices.Clone(xs))), "QuickMedian n=%d pos=%d", n, pos)
+			assert.True(t, math.IsNaN(QuickMedianLower(slices.Clone(xs))), "QuickMedianLower n=%d pos=%d", n, pos)
+			assert.True(t, math.IsNaN(medianAsFloat(slices.Clone(xs))), "medianAsFloat n=%d pos=%d", n, pos)
+
+			// without the NaN, the median is that of the remaining values
+			clean := CleanSamples(xs)
+			if len(clean) > 0 {
+				assert.Equal(t, Median(clean), QuickMedian(clean), "n=%d pos=%d", n, pos)
+			}
+		}
+	}
+	// infinities are ordered and do not make the median NaN
+	assert.Equal(t, 2.0, QuickMedian([]float64{math.Inf(1), 2, math.Inf(-1)}))
+	assert.Equal(t, math.Inf(1), QuickMedian([]float64{math.Inf(1), math.Inf(1), 1}))
+	assert.Equal(t, 3.0, medianAsFloat([]int64{5, 1, 4, 2, 3}))
+}
+
+func TestBootstrapConfidenceNaNReplicates(t *testing.T) {
+	A := []float64{90, 91, 89, 88, 92, 93, 87, 94, 86, 95, 90}
+	B := []float64{100, 101, 99, 98, 102, 103, 97, 104, 96, 105, 100}
+	withNaN := append(slices.Clone(A), math.NaN())
+	clean := BootstrapConfidence(A, B, []float64{0}, 2000, 5)[0]
+	dirty := BootstrapConfidence(withNaN, B, []float64{0}, 2000, 5)[0]
+	assert.Equal(t, 1.0, clean)
+	// a replicate of n values draws the NaN with probability 1-(1-1/n)^n (about 65% for n = 12)
+	assert.InDelta(t, math.Pow(11.0/12, 12), dirty, 0.05)
+}
+
+func TestWilsonLowerBound(t *testing.T) {
+	// reference values of the 95% Wilson score interval
+	assert.InDelta(t, 0.4038, wilsonLowerBound(0.5, 100), 1e-4)
+	assert.InDelta(t, 0.7864, wilsonLowerBound(0.9, 50), 1e-4)
+	assert.InDelta(t, 0.99923, wilsonLowerBound(1, 5000), 1e-5)
+	assert.Equal(t, 0.0, wilsonLowerBound(0, 1000))
+	assert.Less(t, wilsonLowerBound(0.95, 1000), 0.95)
+	assert.Less(t, wilsonLowerBound(0.95, 1000), wilsonLowerBound(0.95, 100_000), "bound must tighten with more resamples")
+	assert.True(t, math.IsNaN(wilsonLowerBound(math.NaN(), 100)))
+	assert.True(t, math.IsNaN(wilsonLowerBound(0.5, 0)))
+}
+
+func TestMoments(t *testing.T) {
+	mean, variance, skewness, excessKurtosis := Moments([]float64{1, 2, 3})
+	assert.InDelta(t, 2.0, mean, 1e-12)
+	assert.InDelta(t, 2/3.0, variance, 1e-12)
+	assert.InDelta(t, 0.0, skewness, 1e-12)
+	assert.InDelta(t, -1.5, excessKurtosis, 1e-12)
+
+	// two-point distribution with p = 1/4: skewness (1-2p)/sqrt(p(1-p)), excess kurtosis (1-6p(1-p))/(p(1-p))
+	_, _, skewness, excessKurtosis = Moments([]float64{1, 1, 1, 10})
+	assert.InDelta(t, 2/math.Sqrt(3), skewness, 1e-12)
+	assert.InDelta(t, -2/3.0, excessKurtosis, 1e-12)
+
+	// mirrored data has mirrored skewness
+	_, _, skewness, _ = Moments([]float64{-1, -1, -1, -10})
+	assert.InDelta(t, -2/math.Sqrt(3), skewness, 1e-12)
+
+	mean, variance, skewness, excessKurtosis = Moments([]float64{5, 5, 5})
+	assert.Equal(t, []float64{5, 0, 0, 0}, []float64{mean, variance, skewness, excessKurtosis})
+
+	mean, variance, skewness, excessKurtosis = Moments(nil)
+	assert.Equal(t, 0.0, mean)
+	assert.Equal(t, -1.0, variance)
+	assert.True(t, math.IsNaN(skewness))
+	assert.True(t, math.IsNaN(excessKurtosis))
+}
+
+// lomutoPartition rearranges xs[low..high] around the pivot xs[high] with Lomuto's scheme and returns
+// the final index of the pivot. It is the baseline for BenchmarkSelectKthDuplicates.
+func lomutoPartition[T number](xs []T, low, high uint64) uint64 {
+	pivot := xs[high]
+	i := low
+	for j := low; j < high; j++ {
+		if xs[j] < pivot {
+			xs[i], xs[j] = xs[j], xs[i]
+			i++
+		}
+	}
+	xs[i], xs[high] = xs[high], xs[i]
+	return i
+}
+
+// hoarePartition partitions xs[low..high] (low < high) around the pivot value xs[low] with Hoare's scheme
+// and returns an index j with low <= j < high such that xs[low..j] <= pivot <= xs[j+1..high].
+// It is a baseline for BenchmarkSelectKthDuplicates.
+func hoarePartition[T number](xs []T, low, high int) int {
+	pivot := xs[low]
+	i, j := low-1, high+1
+	for {
+		for i++; xs[i] < pivot; i++ {
+		}
+		for j--; xs[j] > pivot; j-- {
+		}
+		if i >= j {
+			return j
+		}
+		xs[i], xs[j] = xs[j], xs[i]
+	}
+}
+
+// selectKthHoare is selectKth with hoarePartition instead of partition3.
+func selectKthHoare[T number](xs []T, k uint64) T {
+	rng := NewDPRNG()
+	kk := int(k)
+	low, high := 0, len(xs)-1
+	for low < high {
+		pivotIndex := low + int(rng.Uint64()%uint64(high-low+1))
+		xs[pivotIndex], xs[low] = xs[low], xs[pivotIndex]
+		j := hoarePartition(xs, low, high)
+		if kk <= j {
+			high = j
+		} else {
+			low = j + 1
+		}
+	}
+	return xs[kk]
+}
+
+// selectKthLomuto is selectKth with lomutoPartition instead of partition3.
+func selectKthLomuto[T number](xs []T, k uint64) T {
+	rng := NewDPRNG()
+	low, high := uint64(0), uint64(len(xs)-1)
+	for low <= high {
+		pivotIndex := rng.Uint64()%(high-low+1) + low
+		xs[pivotIndex], xs[high] = xs[high], xs[pivotIndex] // move pivot to end
+		p := lomutoPartition(xs, low, high)
+		if p == k {
+			return xs[p]
+		} else if p < k {
+			low = p + 1
+		} else {
+			high = p - 1
+		}
+	}
+	return xs[k]
+}
+
+func TestSelectKthDuplicates(t *testing.T) {
+	rng := NewDPRNG(7)
+	for _, distinct := range []uint32{1, 2, 3, 10, 1 << 30} {
+		for n := 1; n <= 200; n++ {
+			xs := make([]float64, n)
+			for i := range xs {
+				xs[i] = float64(rng.UInt32N(distinct))
+			}
+			sorted := slices.Sorted(slices.Values(xs))
+			for k := range n {
+				assert.Equal(t, sorted[k], selectKth(slices.Clone(xs), uint64(k)), "distinct=%d n=%d k=%d", distinct, n, k)
+			}
+			for _, k := range []int{0, n / 2, n - 1} {
+				assert.Equal(t, sorted[k], selectKthHoare(slices.Clone(xs), uint64(k)), "distinct=%d n=%d k=%d", distinct, n, k)
+				assert.Equal(t, sorted[k], selectKthLomuto(slices.Clone(xs), uint64(k)), "distinct=%d n=%d k=%d", distinct, n, k)
+			}
+		}
+	}
+}
+
+func TestPartition3(t *testing.T) {
+	rng := NewDPRNG(8)
+	for _, distinct := range []uint32{1, 2, 5, 1 << 30} {
+		for n := 2; n <= 100; n++ {
+			xs := make([]int64, n)
+			for i := range xs {
+				xs[i] = int64(rng.UInt32N(distinct))
+			}
+			pivot := xs[0]
+			lt, gt := partition3(xs, 0, n-1)
+			assert.LessOrEqual(t, lt, gt)
+			for i, x := range xs {
+				switch {
+				case i < lt:
+					assert.Less(t, x, pivot)
+				case i <= gt:
+					assert.Equal(t, pivot, x)
+				default:
+					assert.Greater(t, x, pivot)
+				}
+			}
+		}
+	}
+}
+
+// BenchmarkSelectKthDuplicates compares Lomuto, Hoare and three-way partitioning (selectKth) on quantized
+// timings with few distinct values, as produced by coarse timers.
+func BenchmarkSelectKthDuplicates(b *testing.B) {
+	for _, distinct := range []uint32{1, 4, 16, 1 << 30} {
+		for _, n := range []int{101, 1001} {
+			rng := NewDPRNG(42)
+			src := make([]float64, n)
+			for i := range src {
+				src[i] = float64(100 + rng.UInt32N(distinct))
+			}
+			xs := make([]float64, n)
+			b.Run(fmt.Sprintf("lomuto/distinct=%d/n=%d", distinct, n), func(b *testing.B) {
+				for b.Loop() {
+					copy(xs, src)
+					selectKthLomuto(xs, uint64(n/2))
+				}
+			})
+			b.Run(fmt.Sprintf("hoare/distinct=%d/n=%d", distinct, n), func(b *testing.B) {
+				for b.Loop() {
+					copy(xs, src)
+					selectKthHoare(xs, uint64(n/2))
+				}
+			})
+			b.Run(fmt.Sprintf("3way/distinct=%d/n=%d", distinct, n), func(b *testing.B) {
+				for b.Loop() {
+					copy(xs, src)
+					selectKth(xs, uint64(n/2))
+				}
+			})
+		}
+	}
+}
+
+func TestMedians(t *testing.T) {
+	rng := NewDPRNG(12)
+	groups := [][]float64{{}, {3}, {2, 1}, {5, 1, 3}, {math.NaN(), 1, 2}}
+	for n := 4; n <= 120; n += 7 {
+		g := make([]float64, n)
+		rng.FillFloat64(g)
+		groups = append(groups, g)
+	}
+	orig := make([][]float64, len(groups))
+	for i, g := range groups {
+		orig[i] = slices.Clone(g)
+	}
+
+	medians := Medians(groups)
+	if assert.Len(t, medians, len(groups)) {
+		assert.True(t, math.IsNaN(medians[0]), "empty group")
+		assert.Equal(t, 3.0, medians[1])
+		assert.Equal(t, 2.0, medians[2])
+		assert.Equal(t, 3.0, medians[3])
+		assert.True(t, math.IsNaN(medians[4]), "group with NaN")
+		for i := 5; i < len(groups); i++ {
+			assert.Equal(t, Median(groups[i]), medians[i], "group %d", i)
+		}
+	}
+	for i := range groups {
+		if i != 4 { // NaN != NaN
+			assert.Equal(t, orig[i], groups[i], "group %d must not be modified", i)
+		}
+	}
+	assert.Nil(t, Medians(nil))
+	assert.Empty(t, Medians([][]float64{}))
+}
+
+func BenchmarkMedians(b *testing.B) {
+	rng := NewDPRNG(42)
+	groups := make([][]float64, 1000)
+	for i := range groups {
+		groups[i] = make([]float64, 5+i%20)
+		rng.FillFloat64(groups[i])
+	}
+	b.Run("Medians", func(b *testing.B) {
+		for b.Loop() {
+			Medians(groups)
+		}
+	})
+	b.Run("QuickMedian", func(b *testing.B) {
+		medians := make([]float64, len(groups))
+		for b.Loop() {
+			for i, g := range groups {
+				medians[i] = QuickMedian(slices.Clone(g))
+			}
+		}
+	})
+}
@@ -0,0 +1,251 @@
+package rtcompare
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"time"
+)
+
+// MeasureAllocBytes runs f once and returns the number of heap bytes allocated during its execution.
+// Collect the results of repeated calls into a []float64 and pass them to CompareSamples to compare
+// the memory consumption of two implementations.
+//
+// GC interaction: MeasureAllocBytes forces a garbage collection cycle before calling f, so that
+// pending sweeping work of earlier allocations does not interfere with the measurement. The result
+// is derived from the cumulative runtime.MemStats.TotalAlloc counter rather than from the current
+// HeapAlloc value, so garbage collection cycles triggered while f runs do not reduce the reported
+// number. Memory that f allocated and that is already garbage by the time f returns is therefore
+// still counted. Allocations made concurrently by other goroutines are counted as well.
+//
+// Note that runtime.ReadMemStats stops the world and runtime.GC is expensive. Both are called
+// outside of f, but make sure that f does a meaningful amount of work per call.
+func MeasureAllocBytes(f func()) float64 {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	f()
+	runtime.ReadMemStats(&after)
+	return float64(after.TotalAlloc - before.TotalAlloc)
+}
+
+// MeasureAllocCount runs f once and returns the number of heap allocations (runtime.MemStats.Mallocs)
+// made during its execution, complementing MeasureAllocBytes for the common Go goal of fewer allocations.
+// Collect the results of repeated calls into a []float64 and pass them to CompareSamples to confirm that
+// an implementation allocates less often. As with MeasureAllocBytes, a garbage collection cycle is forced
+// before f runs, the count is cumulative, so collections during f do not reduce it, and allocations made
+// concurrently by other goroutines are counted as well. Allocations the compiler places on the stack are
+// not counted.
+//
+// Cost: runtime.ReadMemStats stops the world and runtime.GC is expensive, so each call takes far longer
+// than a small f. To count the allocations of a cheap operation, run it many times within f and divide
+// the result by that count, measuring in batches rather than per call.
+func MeasureAllocCount(f func()) float64 {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	f()
+	runtime.ReadMemStats(&after)
+	return float64(after.Mallocs - before.Mallocs)
+}
+
+// DefaultPrecisionMultiple is the factor applied to GetSampleTimePrecision by CalibrateInnerLoops
+// when no explicit minimum sample duration is given. A timed window of 1000 timer ticks limits the
+// quantization error of a single timing sample to about 0.1%.
+const DefaultPrecisionMultiple = 1000
+
+// CalibrateInnerLoops returns the number of consecutive calls of f that need to be timed together
+// so that a single timing sample (measured with SampleTime before and after the inner loop) lasts at
+// least minSampleNanos nanoseconds. This removes the guesswork of choosing innerLoops by hand and
+// makes measurements robust across machines with different timer resolutions.
+//
+// If minSampleNanos is zero or negative, DefaultPrecisionMultiple × GetSampleTimePrecision() is used.
+//
+// The function first times a single call of f, extrapolates the required count from the observed
+// per-call time and re-measures with that count until the timed window is long enough. Calls of f
+// faster than the timer resolution are handled by growing the count tenfold until a duration can be
+// observed. f is therefore called several times; it must not have side effects that prevent this.
+// The result is at least 1.
+func CalibrateInnerLoops(f func(), minSampleNanos int64) int {
+	if minSampleNanos <= 0 {
+		minSampleNanos = DefaultPrecisionMultiple * GetSampleTimePrecision()
+	}
+	count := 1
+	for {
+		t1 := SampleTime()
+		for range count {
+			f()
+		}
+		t2 := SampleTime()
+		elapsed := AbsDiffTimeStamps(t1, t2)
+		if elapsed >= minSampleNanos {
+			return count
+		}
+		next := count * 10
+		if elapsed > 0 {
+			// extrapolate with 20% headroom, but grow at most 100-fold per step
+			// to limit the impact of a single disturbed measurement
+			perCall := float64(elapsed) / float64(count)
+			estimate := math.Ceil(float64(minSampleNanos) / perCall * 1.2)
+			next = int(math.Min(estimate, float64(count)*100))
+		}
+		if next <= count {
+			next = count + 1
+		}
+		count = next
+	}
+}
+
+// WithGCOff runs f with the garbage collector disabled (debug.SetGCPercent(-1)) and restores the previous
+// GC percentage afterwards, even if f panics. Use it around a measurement window of allocation-free code
+// to rule out GC-induced timing noise entirely, instead of merely calling runtime.GC() between samples.
+//
+// Memory-pressure caveat: while the GC is off, the heap grows without bound with every allocation, so
+// code that allocates may exhaust memory, and the collection that was postponed happens (and costs time)
+// after f returns. A memory limit set with debug.SetMemoryLimit still triggers collections.
+// The GC percentage is a process-wide setting, so concurrently running code is affected as well.
+func WithGCOff(f func()) {
+	previous := debug.SetGCPercent(-1)
+	defer debug.SetGCPercent(previous)
+	f()
+}
+
+// Sink is a package-level destination for the results of measured code. Assigning a result to Sink
+// (instead of discarding it with `_ =`) is the recommended way to keep the compiler from eliminating a
+// call whose result is otherwise unused: a store to a package-level variable is an observable side effect
+// the compiler cannot remove. MeasureFunc stores every result of the measured function here.
+// Sink is not synchronized; do not assign to it from concurrently running goroutines.
+var Sink any
+
+// MeasureFunc collects `samples` timing samples of f and returns the runtime per call of f in nanoseconds
+// for each sample, ready to be passed to CompareSamples. Each sample times `innerLoops` consecutive calls
+// of f (see PerOpNanos); if innerLoops is zero or negative, the count is determined with
+// CalibrateInnerLoops and DefaultPrecisionMultiple.
+//
+// Every return value of f is stored in Sink, guaranteeing that the work producing it is not optimized
+// away. Returning a non-pointer value larger than a machine word from f may allocate when it is converted
+// to any; return a pointer or a small value if such allocations would distort the measurement.
+// Returns nil if samples is zero or negative.
+func MeasureFunc(f func() any, samples, innerLoops int) []float64 {
+	if samples <= 0 {
+		return nil
+	}
+	if innerLoops <= 0 {
+		innerLoops = CalibrateInnerLoops(func() { Sink = f() }, 0)
+	}
+	result := make([]float64, samples)
+	for i := range result {
+		t1 := SampleTime()
+		for range innerLoops {
+			Sink = f()
+		}
+		t2 := SampleTime()
+		result[i] = PerOpNanos(t1, t2, innerLoops)
+	}
+	return result
+}
+
+// Benchmark collects `repeats` timing samples of f and returns the runtime per call of f in nanoseconds for
+// each sample, like MeasureFunc. Each sample times `innerLoops` consecutive calls of f; if innerLoops is zero
+// or negative, the count is determined with CalibrateInnerLoops and DefaultPrecisionMultiple.
+//
+// Unlike MeasureFunc, Benchmark refuses to record samples that are dominated by quantization noise: if the
+// timed window of a sample is shorter than MinMeaningfulDuration, it stops and returns nil and an error
+// wrapping ErrWindowTooShort. Increase innerLoops (or pass zero) in that case. Keep the results of the
+// measured code alive by assigning them to Sink. Returns nil and no error if repeats is zero or negative.
+func Benchmark(f func(), repeats, innerLoops int) ([]float64, error) {
+	if repeats <= 0 {
+		return nil, nil
+	}
+	if innerLoops <= 0 {
+		innerLoops = CalibrateInnerLoops(f, 0)
+	}
+	minWindow := MinMeaningfulDuration().Nanoseconds()
+	result := make([]float64, repeats)
+	for i := range result {
+		t1 := SampleTime()
+		for range innerLoops {
+			f()
+		}
+		t2 := SampleTime()
+		if window := AbsDiffTimeStamps(t1, t2); window < minWindow {
+			return nil, fmt.Errorf("%w: %d calls took %d ns, below the minimum of %d ns (%d × timer precision); increase innerLoops",
+				ErrWindowTooShort, innerLoops, window, minWindow, MinMeaningfulDurationMultiple)
+		}
+		result[i] = PerOpNanos(t1, t2, innerLoops)
+	}
+	return result, nil
+}
+
+// BenchmarkForDuration collects timing samples of f for a time budget instead of a fixed number of repeats,
+// similar to go test -benchtime, e.g. to fit a CI time limit. It returns the runtime per call of f in
+// nanoseconds for each sample, like Benchmark. Each sample times a number of consecutive calls of f that is
+// determined with CalibrateInnerLoops(f, minSampleNanos), so a sample lasts at least minSampleNanos, or
+// DefaultPrecisionMultiple × GetSampleTimePrecision() if minSampleNanos is zero or negative. As Benchmark
+// refuses windows shorter than MinMeaningfulDuration, a positive minSampleNanos below it is raised to
+// MinMeaningfulDuration, so that the samples are not dominated by quantization noise.
+//
+// Samples are collected until the elapsed time since the call, measured with SampleTime and including the
+// calibration, exceeds budget. Since fewer samples cannot be compared, at least MinimumDataPoints samples are
+// always collected, even if this exceeds the budget; budget is thus a soft limit, overrun by at most one
+// sample or by the time needed for MinimumDataPoints samples. Keep the results of the measured code alive by
+// assigning them to Sink.
+func BenchmarkForDuration(f func(), budget time.Duration, minSampleNanos int64) []float64 {
+	start := SampleTime()
+	if minSampleNanos > 0 {
+		minSampleNanos = max(minSampleNanos, MinMeaningfulDuration().Nanoseconds())
+	}
+	innerLoops := CalibrateInnerLoops(f, minSampleNanos)
+	var result []float64
+	for uint64(len(result)) < MinimumDataPoints || DiffTimeStamps(start, SampleTime()) < budget.Nanoseconds() {
+		t1 := SampleTime()
+		for range innerLoops {
+			f()
+		}
+		t2 := SampleTime()
+		result = append(result, PerOpNanos(t1, t2, innerLoops))
+	}
+	return result
+}
+
+// Interleave measures several functions in randomized order to defeat systematic order bias. Measuring
+// the candidates in a fixed order in every repeat favors whichever runs in a better position, e.g. second
+// with warm caches. Interleave instead runs each function of measure once per repeat, in an order that is
+// shuffled anew for every repeat with a DPRNG seeded with seed (zero selects a random seed, see NewDPRNG).
+//
+// It returns the timing samples per name: result[name][i] is the runtime of the call of measure[name]
+// in repeat i in nanoseconds. Each call is timed individually, so let the function execute enough
+// iterations of the code under test to exceed the timer precision (see CalibrateInnerLoops) and divide
+// the samples by that count if per-iteration times are needed. Keep the results of the measured code
+// alive by assigning them to Sink.
+// Returns an empty map if measure is empty or repeats is zero or negative.
+func Interleave(measure map[string]func(), repeats int, seed uint64) map[string][]float64 {
+	result := make(map[string][]float64, len(measure))
+	if len(measure) == 0 || repeats <= 0 {
+		return result
+	}
+	// sort the names first so the order for a given seed does not depend on map iteration order
+	names := make([]string, 0, len(measure))
+	for name := range measure {
+		names = append(names, name)
+		result[name] = make([]float64, repeats)
+	}
+	slices.Sort(names)
+	rng := NewDPRNG(seed)
+	for i := range repeats {
+		for j := len(names) - 1; j > 0; j-- {
+			k := uint64n(&rng, uint64(j+1))
+			names[j], names[k] = names[k], names[j]
+		}
+		for _, name := range names {
+			f := measure[name]
+			t1 := SampleTime()
+			f()
+			t2 := SampleTime()
+			result[name][i] = float64(DiffTimeStamps(t1, t2))
+		}
+	}
+	return result
+}
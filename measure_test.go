@@ -0,0 +1,240 @@
+package rtcompare
+
+import (
+	"runtime/debug"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var measureSink []byte
+
+func TestMeasureAllocBytes(t *testing.T) {
+	const size = 1 << 20
+	got := MeasureAllocBytes(func() {
+		measureSink = make([]byte, size)
+	})
+	assert.True(t, got >= size, "expected at least %d bytes, got %.0f", size, got)
+	assert.True(t, got < 2*size, "expected less than %d bytes, got %.0f", 2*size, got)
+
+	none := MeasureAllocBytes(func() {})
+	assert.True(t, none < 1024, "expected (almost) no allocations for empty function, got %.0f", none)
+}
+
+func TestMeasureAllocBytesCompareSamples(t *testing.T) {
+	var small, large []float64
+	for range 21 {
+		small = append(small, MeasureAllocBytes(func() { measureSink = make([]byte, 1<<10) }))
+		large = append(large, MeasureAllocBytes(func() { measureSink = make([]byte, 1<<14) }))
+	}
+	results, err := CompareSamples(small, large, []float64{0.5}, 1000)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, results[0].Confidence)
+}
+
+var measureSinks [][]byte
+
+func TestMeasureAllocCount(t *testing.T) {
+	got := MeasureAllocCount(func() {
+		measureSinks = make([][]byte, 100)
+		for i := range measureSinks {
+			measureSinks[i] = make([]byte, 64)
+		}
+	})
+	assert.True(t, got >= 101, "expected at least 101 allocations, got %.0f", got)
+	assert.True(t, got < 120, "expected about 101 allocations, got %.0f", got)
+
+	none := MeasureAllocCount(func() {})
+	assert.True(t, none < 5, "expected (almost) no allocations for empty function, got %.0f", none)
+}
+
+func TestMeasureAllocCountCompareSamples(t *testing.T) {
+	var few, many []float64
+	for range 21 {
+		few = append(few, MeasureAllocCount(func() { measureSink = make([]byte, 1<<14) }))
+		many = append(many, MeasureAllocCount(func() {
+			for range 16 {
+				measureSink = make([]byte, 1<<10)
+			}
+		}))
+	}
+	results, err := CompareSamples(few, many, []float64{0.5}, 1000)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, results[0].Confidence)
+}
+
+var calibrateSink float64
+
+func calibrateWork() {
+	x := 1.0
+	for i := range 100 {
+		x = x*1.0000001 + float64(i)
+	}
+	calibrateSink = x
+}
+
+func TestCalibrateInnerLoops(t *testing.T) {
+	const minSampleNanos = 2_000_000 // 2ms
+	count := CalibrateInnerLoops(calibrateWork, minSampleNanos)
+	assert.True(t, count > 1, "expected more than one call for a 2ms window, got %d", count)
+
+	// the calibrated count must yield windows of (roughly) the requested length
+	samples := make([]float64, 0, 11)
+	for range 11 {
+		t1 := SampleTime()
+		for range count {
+			calibrateWork()
+		}
+		t2 := SampleTime()
+		samples = append(samples, float64(DiffTimeStamps(t1, t2)))
+	}
+	assert.True(t, QuickMedian(samples) >= minSampleNanos/2, "median window %.0f ns too short for count %d", QuickMedian(samples), count)
+}
+
+func TestCalibrateInnerLoopsSlowFunction(t *testing.T) {
+	count := CalibrateInnerLoops(func() { time.Sleep(2 * time.Millisecond) }, 1_000_000)
+	assert.Equal(t, 1, count, "a single call already exceeds the minimum duration")
+}
+
+func TestCalibrateInnerLoopsDefault(t *testing.T) {
+	count := CalibrateInnerLoops(calibrateWork, 0)
+	assert.True(t, count >= 1)
+}
+
+func TestMeasureFunc(t *testing.T) {
+	calls := 0
+	times := MeasureFunc(func() any { calls++; return calls }, 5, 3)
+	assert.Len(t, times, 5)
+	assert.Equal(t, 15, calls)
+	assert.Equal(t, 15, Sink, "the last result must be stored in Sink")
+	for _, d := range times {
+		assert.True(t, d >= 0, "negative duration %v", d)
+	}
+	assert.Nil(t, MeasureFunc(func() any { return nil }, 0, 1))
+}
+
+func TestMeasureFuncCalibrated(t *testing.T) {
+	work := func() any { calibrateWork(); return calibrateSink }
+	times := MeasureFunc(work, 11, 0)
+	assert.Len(t, times, 11)
+	assert.True(t, QuickMedian(times) > 0, "expected a positive per-call duration")
+}
+
+func TestBenchmark(t *testing.T) {
+	defer resetSampleTimePrecision()()
+	precisionOnce.Do(func() {})
+	precision.Store(50)
+	assert.Equal(t, 5*time.Microsecond, MinMeaningfulDuration())
+
+	calls := 0
+	times, err := Benchmark(func() { calls++ }, 3, 1)
+	assert.ErrorIs(t, err, ErrWindowTooShort)
+	assert.Nil(t, times)
+	assert.Equal(t, 1, calls, "Benchmark must stop at the first short window")
+
+	times, err = Benchmark(func() { time.Sleep(10 * time.Microsecond) }, 3, 1)
+	assert.NoError(t, err)
+	assert.Len(t, times, 3)
+	for _, d := range times {
+		assert.True(t, d >= 10_000, "duration %v shorter than the sleep", d)
+	}
+
+	times, err = Benchmark(calibrateWork, 5, 0)
+	assert.NoError(t, err)
+	assert.Len(t, times, 5)
+
+	times, err = Benchmark(calibrateWork, 0, 0)
+	assert.NoError(t, err)
+	assert.Nil(t, times)
+}
+
+func TestBenchmarkForDuration(t *testing.T) {
+	GetSampleTimePrecision() // the first call measures the timer, do not charge it to the budget
+	start := time.Now()
+	times := BenchmarkForDuration(func() { time.Sleep(time.Millisecond) }, 50*time.Millisecond, 1)
+	elapsed := time.Since(start)
+	assert.GreaterOrEqual(t, len(times), int(MinimumDataPoints))
+	assert.GreaterOrEqual(t, elapsed, 50*time.Millisecond)
+	assert.Less(t, elapsed, 500*time.Millisecond, "the budget must not be overrun by much")
+	for _, d := range times {
+		assert.True(t, d >= 1e6, "duration %v shorter than the sleep", d)
+	}
+
+	// a tiny budget still yields the minimum number of samples
+	calls := 0
+	times = BenchmarkForDuration(func() { calls++ }, 0, 1)
+	assert.Len(t, times, int(MinimumDataPoints))
+	assert.True(t, calls >= int(MinimumDataPoints))
+
+	// a minSampleNanos below MinMeaningfulDuration is raised to it, as Benchmark would refuse shorter windows
+	defer resetSampleTimePrecision()()
+	precisionOnce.Do(func() {})
+	precision.Store(50)
+	var busy int64
+	times = BenchmarkForDuration(func() {
+		t1 := SampleTimeNanos()
+		for SampleTimeNanos()-t1 < 100 {
+		}
+		busy += SampleTimeNanos() - t1
+	}, 0, 1)
+	assert.Len(t, times, int(MinimumDataPoints))
+	// without the clamp, every sample would time a single call of about 100 ns
+	minBusy := int64(MinimumDataPoints) * MinMeaningfulDuration().Nanoseconds() / 2
+	assert.GreaterOrEqual(t, busy, minBusy, "samples must not be shorter than MinMeaningfulDuration")
+
+	times = BenchmarkForDuration(calibrateWork, 20*time.Millisecond, 0)
+	assert.GreaterOrEqual(t, len(times), int(MinimumDataPoints))
+	assert.True(t, QuickMedian(times) > 0)
+}
+
+func TestInterleave(t *testing.T) {
+	var order []string
+	record := func(name string) func() { return func() { order = append(order, name) } }
+	measure := map[string]func(){"a": record("a"), "b": record("b"), "c": record("c")}
+
+	samples := Interleave(measure, 200, 42)
+	assert.Len(t, samples, 3)
+	for name, times := range samples {
+		assert.Len(t, times, 200, name)
+		for _, d := range times {
+			assert.True(t, d >= 0, "negative duration %v", d)
+		}
+	}
+	assert.Len(t, order, 600)
+
+	// every function runs exactly once per repeat, and each one runs first in some repeats
+	first := map[string]int{}
+	for i := 0; i < len(order); i += 3 {
+		repeat := slices.Clone(order[i : i+3])
+		slices.Sort(repeat)
+		assert.Equal(t, []string{"a", "b", "c"}, repeat)
+		first[order[i]]++
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		assert.Greater(t, first[name], 40, "%s should run first in about a third of the repeats", name)
+	}
+
+	// the order is reproducible for a fixed seed
+	firstOrder := slices.Clone(order)
+	order = nil
+	Interleave(measure, 200, 42)
+	assert.Equal(t, firstOrder, order)
+
+	assert.Empty(t, Interleave(nil, 10, 1))
+	assert.Empty(t, Interleave(measure, 0, 1))
+}
+
+func TestWithGCOff(t *testing.T) {
+	previous := debug.SetGCPercent(150)
+	defer debug.SetGCPercent(previous)
+
+	WithGCOff(func() {
+		assert.Equal(t, -1, debug.SetGCPercent(-1), "GC must be disabled inside f")
+	})
+	assert.Equal(t, 150, debug.SetGCPercent(150), "GC percentage must be restored")
+
+	assert.Panics(t, func() { WithGCOff(func() { panic("boom") }) })
+	assert.Equal(t, 150, debug.SetGCPercent(150), "GC percentage must be restored after a panic")
+}
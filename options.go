@@ -0,0 +1,136 @@
+package rtcompare
+
+import (
+	"fmt"
+	"math"
+)
+
+// DeltaFunc computes the difference between the summary statistics (e.g. medians) statA and statB of a
+// bootstrap replicate of A and B. Larger values must mean "A is better than B"; a replicate meets a
+// threshold t if DeltaFunc(statA, statB) >= t, so thresholds are interpreted in the units of the delta.
+type DeltaFunc func(statA, statB float64) float64
+
+// RelativeDelta is the DeltaFunc used by CompareSamples: the relative speedup 1 - statA/statB, with the
+// NaN, zero, infinity and tiny-denominator handling documented for BootstrapConfidence. Thresholds are
+// relative fractions, e.g. 0.2 for "A is at least 20% smaller than B".
+func RelativeDelta(statA, statB float64) float64 {
+	return relativeDelta(statA, statB)
+}
+
+// AbsoluteDelta is a DeltaFunc for additive comparisons, e.g. latency budgets: it returns statB - statA.
+// Thresholds are in the units of the measurements, e.g. 50 for "A is at least 50 ns faster than B" when
+// the measurements are nanoseconds. Returns math.NaN() if either statistic is NaN.
+func AbsoluteDelta(statA, statB float64) float64 {
+	return statB - statA
+}
+
+// MinimumDataPointsFloor is the smallest value accepted for CompareOptions.MinimumDataPoints. With fewer
+// measurements, a bootstrap median can only take one or two distinct values and is not meaningful.
+const MinimumDataPointsFloor uint64 = 3
+
+// CompareOptions configures CompareSamplesWithOptions. The zero value selects the behavior of CompareSamples
+// with DefaultResamples.
+type CompareOptions struct {
+	// Resamples is the number of bootstrap resamples. Zero selects DefaultResamples.
+	Resamples uint64
+	// Seed has the same meaning as prngSeed in BootstrapConfidence: a non-zero value makes the result
+	// reproducible, zero uses a CPRNG.
+	Seed uint64
+	// DeltaFunc compares the medians of each bootstrap replicate. Nil selects RelativeDelta.
+	DeltaFunc DeltaFunc
+	// StrictGreater makes a replicate meet a threshold only if its delta is strictly greater than the
+	// threshold. By default (false), as in CompareSamples, delta == threshold meets the threshold, so
+	// for threshold 0 replicates with equal medians count as "A is better than B". Set StrictGreater
+	// if equal should not count as better.
+	StrictGreater bool
+	// MinimumDataPoints is the number of measurements each input must contain at least. Zero selects the
+	// package constant MinimumDataPoints (11). Lower it for exploratory work if wider intervals are acceptable,
+	// raise it for rigorous work. Values below MinimumDataPointsFloor are rejected.
+	MinimumDataPoints uint64
+	// NoShortCircuit disables a shortcut for degenerate inputs: if A and B are each constant (e.g. a metric
+	// that never varies, or the same constant slice passed twice), every bootstrap replicate yields the
+	// observed delta, so the confidences are computed directly as 1 or 0 without running the bootstrap. The
+	// result is identical; set NoShortCircuit to force the full computation, e.g. for benchmarking it.
+	// Note that element-wise identical but non-constant inputs are not degenerate: their resamples differ,
+	// so the bootstrap is always run for them.
+	NoShortCircuit bool
+	// IncludeZeroThreshold adds the threshold 0 to the requested thresholds if they do not contain it yet,
+	// so that every comparison also answers "is A faster at all?" (or, with a DeltaFunc other than
+	// RelativeDelta, "is the delta positive at all?"), e.g. as a baseline for Verdict. Like all thresholds,
+	// it appears in the results in ascending order. The caller's slice of thresholds is not modified.
+	IncludeZeroThreshold bool
+	// QuantizationStep is the resolution of the measurements, e.g. GetSampleTimePrecision()/innerLoops for
+	// per-op times derived from a timer with coarse ticks. If positive, every resampled value is dithered
+	// with uniform noise in [-step/2, step/2) before the medians are computed. Measurements that span only
+	// a few ticks otherwise make the bootstrap medians snap to a handful of grid values, so the confidences
+	// jump between thresholds and ties (delta exactly 0) are over-represented. If the true value is equally
+	// likely to lie anywhere within its tick, the rounded value plus the noise has the distribution of the
+	// true value, so the dithered medians are continuous. This assumes a rounding timer; for a truncating
+	// timer, add step/2 to the measurements first. Zero, the default, disables dithering. Negative or
+	// non-finite values are rejected.
+	QuantizationStep float64
+	// AssumePairedIfEqualLength routes comparisons of inputs with equal lengths to the paired bootstrap of
+	// CompareSamplesPaired: A[i] and B[i] are treated as a pair, and each replicate evaluates the median of
+	// the per-pair deltas DeltaFunc(A[i], B[i]) instead of DeltaFunc(median(A_sample), median(B_sample)).
+	// Inputs of different lengths are compared unpaired as usual.
+	//
+	// Warning: this is only correct if the measurements really are pairs, e.g. A[i] and B[i] were measured
+	// on the same input or with the same seed, close together in time, and neither slice has been sorted or
+	// filtered on its own. For unrelated samples that merely have the same length, the pairing is arbitrary
+	// and the confidences are meaningless. This is why the option is off by default. It cannot be combined
+	// with QuantizationStep, and NewComparator rejects it because a Comparator resamples A and B independently.
+	AssumePairedIfEqualLength bool
+}
+
+// defaultCompareOptions returns the options used by CompareSamples with the given number of resamples.
+func defaultCompareOptions(resamples uint64) CompareOptions {
+	return CompareOptions{Resamples: resamples, DeltaFunc: relativeDelta, MinimumDataPoints: MinimumDataPoints}
+}
+
+// CompareSamplesWithOptions is a variant of CompareSamples that is configured with opts, e.g. to compare
+// absolute instead of relative differences:
+//
+//	CompareSamplesWithOptions(A, B, []float64{50}, CompareOptions{DeltaFunc: AbsoluteDelta})
+//
+// The confidence is the fraction of bootstrap replicates for which
+// opts.DeltaFunc(median(A_sample), median(B_sample)) >= threshold (> threshold with opts.StrictGreater).
+// The thresholds in relativeGains are thus interpreted in the units of the chosen delta, and so is the
+// RelativeSpeedupSampleAvsSampleB field of the results. Parameters, errors and results are otherwise as
+// for CompareSamples, except that the minimum number of measurements is opts.MinimumDataPoints. An error
+// wrapping ErrInvalidOptions is returned if opts.MinimumDataPoints is non-zero but below MinimumDataPointsFloor
+// or if opts.QuantizationStep is negative or not finite. With opts.AssumePairedIfEqualLength, inputs of equal
+// length are compared as pairs (see CompareOptions).
+func CompareSamplesWithOptions(measurementsA, measurementsB []float64, relativeGains []float64, opts CompareOptions) (result []RTcomparisonResult, err error) {
+	opts, err = opts.withDefaults()
+	if err != nil {
+		return []RTcomparisonResult{}, err
+	}
+	if opts.AssumePairedIfEqualLength && len(measurementsA) == len(measurementsB) {
+		return comparePaired(measurementsA, measurementsB, relativeGains, opts)
+	}
+	return compareSamples(measurementsA, measurementsB, relativeGains, opts)
+}
+
+// withDefaults returns opts with the defaults applied to its zero fields, or an error wrapping
+// ErrInvalidOptions if opts is invalid.
+func (opts CompareOptions) withDefaults() (CompareOptions, error) {
+	if opts.Resamples == 0 {
+		opts.Resamples = DefaultResamples
+	}
+	if opts.DeltaFunc == nil {
+		opts.DeltaFunc = relativeDelta
+	}
+	if opts.MinimumDataPoints == 0 {
+		opts.MinimumDataPoints = MinimumDataPoints
+	}
+	if opts.MinimumDataPoints < MinimumDataPointsFloor {
+		return opts, fmt.Errorf("%w: MinimumDataPoints must be at least %d, got %d", ErrInvalidOptions, MinimumDataPointsFloor, opts.MinimumDataPoints)
+	}
+	if !(opts.QuantizationStep >= 0) || math.IsInf(opts.QuantizationStep, 1) {
+		return opts, fmt.Errorf("%w: QuantizationStep must be a finite non-negative number, got %v", ErrInvalidOptions, opts.QuantizationStep)
+	}
+	if opts.AssumePairedIfEqualLength && opts.QuantizationStep > 0 {
+		return opts, fmt.Errorf("%w: QuantizationStep cannot be combined with AssumePairedIfEqualLength", ErrInvalidOptions)
+	}
+	return opts, nil
+}
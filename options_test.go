@@ -0,0 +1,255 @@
+package rtcompare
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareSamplesWithOptionsDefaults(t *testing.T) {
+	rng := NewDPRNG(42)
+	A := make([]float64, 50)
+	B := make([]float64, 50)
+	for i := range A {
+		A[i] = 80 + rng.Float64()*10
+		B[i] = 100 + rng.Float64()*10
+	}
+	gains := []float64{0, 0.1, 0.2, 0.3}
+	got, err := CompareSamplesWithOptions(A, B, gains, CompareOptions{Seed: 7})
+	assert.NoError(t, err)
+	want := BootstrapConfidence(A, B, gains, DefaultResamples, 7)
+	for _, r := range got {
+		assert.Equal(t, want[r.RelativeSpeedupSampleAvsSampleB], r.Confidence)
+		assert.Equal(t, EstimatorMedian, r.Estimator)
+	}
+
+	explicit, err := CompareSamplesWithOptions(A, B, gains, CompareOptions{Resamples: DefaultResamples, Seed: 7, DeltaFunc: RelativeDelta})
+	assert.NoError(t, err)
+	assert.Equal(t, got, explicit)
+}
+
+func TestCompareSamplesWithOptionsAbsoluteDelta(t *testing.T) {
+	A := make([]float64, 21)
+	B := make([]float64, 21)
+	for i := range A {
+		A[i] = 1000 + float64(i)
+		B[i] = 1050 + float64(i)
+	}
+	// the medians differ by exactly 50 units, so every replicate is close to 50
+	results, err := CompareSamplesWithOptions(A, B, []float64{0, 20, 80}, CompareOptions{Resamples: 2000, Seed: 1, DeltaFunc: AbsoluteDelta})
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+	assert.Equal(t, 1.0, results[0].Confidence)
+	assert.Greater(t, results[1].Confidence, 0.95)
+	assert.Less(t, results[2].Confidence, 0.05)
+
+	_, err = CompareSamplesWithOptions(A[:5], B, nil, CompareOptions{DeltaFunc: AbsoluteDelta})
+	assert.ErrorIs(t, err, ErrTooFewDataPoints)
+}
+
+func TestDeltaFuncs(t *testing.T) {
+	assert.Equal(t, 0.5, RelativeDelta(50, 100))
+	assert.Equal(t, 50.0, AbsoluteDelta(50, 100))
+	assert.Equal(t, -50.0, AbsoluteDelta(100, 50))
+	assert.True(t, math.IsNaN(AbsoluteDelta(math.NaN(), 1)))
+}
+
+func TestCompareSamplesWithOptionsObservedDelta(t *testing.T) {
+	A := []float64{10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}
+	B := []float64{30, 31, 32, 33, 34, 35, 36, 37, 38, 39, 40}
+	results, err := CompareSamplesWithOptions(A, B, nil, CompareOptions{Resamples: 10, Seed: 1, DeltaFunc: AbsoluteDelta})
+	assert.NoError(t, err)
+	assert.Equal(t, 20.0, results[0].ObservedDelta)
+}
+
+func TestCompareSamplesWithOptionsStrictGreater(t *testing.T) {
+	A := make([]float64, 11)
+	B := make([]float64, 11)
+	for i := range A {
+		A[i] = 100
+		B[i] = 100
+	}
+	// identical samples: every replicate has delta == 0
+	inclusive, err := CompareSamplesWithOptions(A, B, []float64{0}, CompareOptions{Resamples: 100, Seed: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, inclusive[0].Confidence, "by default equal counts as meeting threshold 0")
+	strict, err := CompareSamplesWithOptions(A, B, []float64{0}, CompareOptions{Resamples: 100, Seed: 1, StrictGreater: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, strict[0].Confidence, "with StrictGreater equal is not faster")
+
+	// away from ties, both modes agree
+	for i := range A {
+		A[i] = 80 + float64(i)
+		B[i] = 100 + float64(i)*1.5
+	}
+	inclusive, _ = CompareSamplesWithOptions(A, B, []float64{0, 0.1, 0.3}, CompareOptions{Resamples: 500, Seed: 3})
+	strict, _ = CompareSamplesWithOptions(A, B, []float64{0, 0.1, 0.3}, CompareOptions{Resamples: 500, Seed: 3, StrictGreater: true})
+	for i := range inclusive {
+		assert.InDelta(t, inclusive[i].Confidence, strict[i].Confidence, 0.05)
+	}
+}
+
+func TestCompareSamplesWithOptionsMinimumDataPoints(t *testing.T) {
+	A := []float64{80, 81, 79, 80, 82}
+	B := []float64{100, 101, 99, 100, 102}
+	_, err := CompareSamplesWithOptions(A, B, nil, CompareOptions{Resamples: 100, Seed: 1})
+	assert.ErrorIs(t, err, ErrTooFewDataPoints, "the default minimum is MinimumDataPoints")
+
+	results, err := CompareSamplesWithOptions(A, B, nil, CompareOptions{Resamples: 100, Seed: 1, MinimumDataPoints: 5})
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, results[0].Confidence)
+
+	_, err = CompareSamplesWithOptions(A, B, nil, CompareOptions{Resamples: 100, Seed: 1, MinimumDataPoints: 6})
+	assert.ErrorIs(t, err, ErrTooFewDataPoints)
+	assert.Contains(t, err.Error(), "at least 6 measurements")
+
+	_, err = CompareSamplesWithOptions(A, B, nil, CompareOptions{MinimumDataPoints: MinimumDataPointsFloor - 1})
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+	_, err = CompareSamplesWithOptions(A[:3], B[:3], nil, CompareOptions{Resamples: 10, MinimumDataPoints: MinimumDataPointsFloor})
+	assert.NoError(t, err)
+}
+
+func TestCompareSamplesShortCircuitMatchesFullComputation(t *testing.T) {
+	constant := func(v float64) []float64 {
+		xs := make([]float64, 11)
+		for i := range xs {
+			xs[i] = v
+		}
+		return xs
+	}
+	same := constant(100)
+	gains := []float64{-0.1, 0, 0.1, 0.2, 0.5}
+	for _, tc := range []struct {
+		name string
+		A, B []float64
+		opts CompareOptions
+	}{
+		{"aliased", same, same, CompareOptions{}},
+		{"identical", constant(100), constant(100), CompareOptions{}},
+		{"identical strict", constant(100), constant(100), CompareOptions{StrictGreater: true}},
+		{"A faster", constant(80), constant(100), CompareOptions{}},
+		{"A faster on the threshold, strict", constant(80), constant(100), CompareOptions{StrictGreater: true}},
+		{"A slower", constant(120), constant(100), CompareOptions{}},
+		{"absolute delta", constant(80), constant(100), CompareOptions{DeltaFunc: AbsoluteDelta}},
+		{"zero", constant(0), constant(0), CompareOptions{}},
+	} {
+		tc.opts.Resamples, tc.opts.Seed = 200, 1
+		short, err := CompareSamplesWithOptions(tc.A, tc.B, gains, tc.opts)
+		assert.NoError(t, err)
+		tc.opts.NoShortCircuit = true
+		full, err := CompareSamplesWithOptions(tc.A, tc.B, gains, tc.opts)
+		assert.NoError(t, err)
+		assert.Equal(t, full, short, tc.name)
+	}
+
+	// identical but non-constant inputs are not degenerate and must not be short-circuited
+	rng := NewDPRNG(3)
+	A := SyntheticSamples(50, 100, 0, 10, &rng)
+	results, err := CompareSamplesWithOptions(A, A, []float64{0}, CompareOptions{Resamples: 2000, Seed: 9})
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.5, results[0].Confidence, 0.1)
+
+	// zero resamples keep yielding NaN
+	results, err = CompareSamples(same, same, []float64{0}, 0)
+	assert.NoError(t, err)
+	assert.True(t, math.IsNaN(results[0].Confidence))
+}
+
+func TestIsConstant(t *testing.T) {
+	assert.True(t, isConstant([]float64{1, 1, 1}))
+	assert.True(t, isConstant([]int64{-5}))
+	assert.False(t, isConstant([]float64{1, 1, 2}))
+	assert.False(t, isConstant([]float64{}))
+	assert.False(t, isConstant([]float64{math.NaN(), math.NaN()}))
+}
+
+func TestCompareSamplesWithOptionsIncludeZeroThreshold(t *testing.T) {
+	rng := NewDPRNG(44)
+	A := SyntheticSamples(30, 95, 0, 3, &rng)
+	B := SyntheticSamples(30, 100, 0, 3, &rng)
+	gains := []float64{0.2, 0.1}
+	opts := CompareOptions{Resamples: 1000, Seed: 2, IncludeZeroThreshold: true}
+
+	got, err := CompareSamplesWithOptions(A, B, gains, opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []float64{0.2, 0.1}, gains, "the caller's thresholds must not be modified")
+	want, err := CompareSamplesWithOptions(A, B, []float64{0, 0.1, 0.2}, CompareOptions{Resamples: 1000, Seed: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	// no duplicate if 0 is requested explicitly, and the default threshold is 0 anyway
+	withZero := []float64{0.1, 0, -0.1}
+	got, err = CompareSamplesWithOptions(A, B, withZero, opts)
+	assert.NoError(t, err)
+	assert.Len(t, got, 3)
+	assert.Equal(t, []float64{0.1, 0, -0.1}, withZero, "thresholds that contain 0 must not be sorted in place")
+	paired := opts
+	paired.AssumePairedIfEqualLength = true
+	_, err = CompareSamplesWithOptions(A, B, withZero, paired)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{0.1, 0, -0.1}, withZero, "thresholds that contain 0 must not be sorted in place")
+	got, err = CompareSamplesWithOptions(A, B, nil, opts)
+	assert.NoError(t, err)
+	assert.Len(t, got, 1)
+
+	c, err := NewComparator(B, opts)
+	if assert.NoError(t, err) {
+		got, err = c.CompareAgainst(A, []float64{0.2, 0.1})
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+}
+
+func TestCompareSamplesWithOptionsQuantizationStep(t *testing.T) {
+	// identically distributed measurements that span only four timer ticks
+	rng := NewDPRNG(3)
+	A := make([]float64, 51)
+	for i := range A {
+		A[i] = math.Round(100 + 3*rng.Float64())
+	}
+	B := slices.Clone(A)
+
+	plain, err := CompareSamplesWithOptions(A, B, nil, CompareOptions{Seed: 9})
+	if !assert.NoError(t, err) {
+		return
+	}
+	dithered, err := CompareSamplesWithOptions(A, B, nil, CompareOptions{Seed: 9, QuantizationStep: 1})
+	if !assert.NoError(t, err) {
+		return
+	}
+	// without dithering, replicates with equal medians all count as "A is not slower"
+	assert.Greater(t, plain[0].Confidence, 0.7)
+	assert.InDelta(t, 0.5, dithered[0].Confidence, 0.05)
+	assert.Equal(t, plain[0].ObservedDelta, dithered[0].ObservedDelta, "the observed delta is not dithered")
+
+	again, _ := CompareSamplesWithOptions(A, B, nil, CompareOptions{Seed: 9, QuantizationStep: 1})
+	assert.Equal(t, dithered, again, "dithering must be reproducible with a seed")
+	assert.Equal(t, B, A, "inputs must not be modified")
+
+	c, err := NewComparator(B, CompareOptions{Seed: 9, QuantizationStep: 1})
+	if !assert.NoError(t, err) {
+		return
+	}
+	viaComparator, err := c.CompareAgainst(A, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, dithered, viaComparator)
+	}
+
+	// constant inputs are no longer degenerate once they are dithered
+	constant := slices.Repeat([]float64{100}, 20)
+	res, err := CompareSamplesWithOptions(constant, constant, nil, CompareOptions{Seed: 9, QuantizationStep: 1})
+	if assert.NoError(t, err) {
+		assert.InDelta(t, 0.5, res[0].Confidence, 0.05)
+	}
+
+	for _, step := range []float64{-1, math.NaN(), math.Inf(1)} {
+		_, err = CompareSamplesWithOptions(A, B, nil, CompareOptions{QuantizationStep: step})
+		assert.ErrorIs(t, err, ErrInvalidOptions, "step %v", step)
+		_, err = NewComparator(B, CompareOptions{QuantizationStep: step})
+		assert.ErrorIs(t, err, ErrInvalidOptions, "step %v", step)
+	}
+}
@@ -0,0 +1,80 @@
+package rtcompare
+
+import (
+	"fmt"
+	"slices"
+)
+
+// EstimatorPairedMedian denotes the median of the per-pair relative differences 1 - A[i]/B[i]. This is the
+// estimator used by CompareSamplesPaired.
+const EstimatorPairedMedian Estimator = "paired median"
+
+// CompareSamplesPaired is a variant of CompareSamples for paired measurements: pairsA[i] and pairsB[i] must
+// have been measured under the same conditions, e.g. on the same input, in lockstep in the same iteration of
+// a measurement loop. Instead of resampling A and B independently, each bootstrap replicate draws pair indices
+// with replacement, keeping the pairs together, and computes the median of the per-pair relative differences
+//
+//	d[i] = 1 - pairsA[i]/pairsB[i]
+//
+// (with the zero and infinity handling documented for BootstrapConfidence). The confidence for a threshold t
+// is the fraction of replicates whose median difference is at least t. Variation shared by both members of a
+// pair, e.g. inputs of very different sizes or slow phases of the machine, cancels out in d[i], so the
+// confidences are considerably tighter than those of CompareSamples on the same data.
+//
+// Pairing is only valid if the measurements really belong together: same input, measured close together in
+// time (ideally in alternating or randomized order, see Interleave), with the pairs being independent of
+// each other. Do not pair measurements that were taken in separate runs or sorted independently; in that
+// case the pairing is arbitrary and CompareSamples is the right choice.
+//
+// Parameters `resamples` and `seed` have the same meaning as `resamples` and `prngSeed` in
+// BootstrapConfidence. The ObservedDelta of the results is the median of all d[i]. An error wrapping
+// ErrUnpairedSamples is returned if pairsA and pairsB differ in length, and an error wrapping
+// ErrTooFewDataPoints if they contain fewer than MinimumDataPoints pairs. The inputs are not modified.
+func CompareSamplesPaired(pairsA, pairsB []float64, relativeGains []float64, resamples, seed uint64) (result []RTcomparisonResult, err error) {
+	opts := defaultCompareOptions(resamples)
+	opts.Seed = seed
+	return comparePaired(pairsA, pairsB, relativeGains, opts)
+}
+
+// comparePaired is the implementation of CompareSamplesPaired and of CompareSamplesWithOptions with
+// AssumePairedIfEqualLength. The per-pair differences are opts.DeltaFunc(pairsA[i], pairsB[i]), and
+// opts.StrictGreater, opts.MinimumDataPoints and opts.IncludeZeroThreshold are applied as for unpaired
+// comparisons. As for compareSamples, defaults must have been applied by the caller.
+func comparePaired(pairsA, pairsB []float64, relativeGains []float64, opts CompareOptions) (result []RTcomparisonResult, err error) {
+	if len(pairsA) != len(pairsB) {
+		return []RTcomparisonResult{}, fmt.Errorf("%w: %d values of A, %d values of B", ErrUnpairedSamples, len(pairsA), len(pairsB))
+	}
+	if uint64(len(pairsA)) < opts.MinimumDataPoints {
+		return []RTcomparisonResult{}, fmt.Errorf("%w: need at least %d pairs", ErrTooFewDataPoints, opts.MinimumDataPoints)
+	}
+	if len(relativeGains) == 0 {
+		relativeGains = []float64{0.0}
+	}
+	relativeGains = withZeroThreshold(relativeGains, opts.IncludeZeroThreshold)
+	slices.Sort(relativeGains)
+
+	diffs := make([]float64, len(pairsA))
+	for i := range diffs {
+		diffs[i] = opts.DeltaFunc(pairsA[i], pairsB[i])
+	}
+	observed := medianAsFloat(slices.Clone(diffs))
+
+	medians := make([]float64, opts.Resamples)
+	sample := make([]float64, len(diffs))
+	for i := range opts.Resamples {
+		s, _ := replicateSeeds(opts.Seed, i)
+		medians[i] = medianAsFloat(bootstrapSampleInto(sample, diffs, s))
+	}
+	conf := thresholdConfidence(medians, relativeGains, opts.StrictGreater)
+
+	for _, t := range relativeGains {
+		result = append(result, RTcomparisonResult{
+			RelativeSpeedupSampleAvsSampleB: t,
+			Confidence:                      conf[t],
+			Estimator:                       EstimatorPairedMedian,
+			ObservedDelta:                   observed,
+			ConfidenceLowerBound:            wilsonLowerBound(conf[t], opts.Resamples),
+		})
+	}
+	return result, nil
+}
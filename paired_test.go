@@ -0,0 +1,111 @@
+package rtcompare
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareSamplesPairedTighterThanUnpaired(t *testing.T) {
+	// inputs of widely differing size dominate the variation; A is 5% faster on every input
+	rng := NewDPRNG(21)
+	const n = 60
+	A := make([]float64, n)
+	B := make([]float64, n)
+	for i := range n {
+		size := 100 + 900*rng.Float64()
+		B[i] = size * (1 + 0.01*NormalQuantile(rng.Float64OpenOpen()))
+		A[i] = 0.95 * size * (1 + 0.01*NormalQuantile(rng.Float64OpenOpen()))
+	}
+	origA, origB := slices.Clone(A), slices.Clone(B)
+
+	paired, err := CompareSamplesPaired(A, B, []float64{0.03}, 2000, 42)
+	assert.NoError(t, err)
+	assert.Equal(t, origA, A, "inputs must not be modified")
+	assert.Equal(t, origB, B, "inputs must not be modified")
+	assert.Len(t, paired, 1)
+	assert.Equal(t, EstimatorPairedMedian, paired[0].Estimator)
+	assert.InDelta(t, 0.05, paired[0].ObservedDelta, 0.01)
+	assert.Greater(t, paired[0].Confidence, 0.99)
+
+	unpaired, err := CompareSamplesWithOptions(A, B, []float64{0.03}, CompareOptions{Resamples: 2000, Seed: 42})
+	assert.NoError(t, err)
+	assert.Less(t, unpaired[0].Confidence, 0.8, "independent resampling should not resolve a 5% speedup")
+}
+
+func TestCompareSamplesPaired(t *testing.T) {
+	A := []float64{90, 91, 89, 88, 92, 93, 87, 94, 86, 95, 90}
+	B := []float64{100, 101, 99, 98, 102, 103, 97, 104, 96, 105, 100}
+
+	res, err := CompareSamplesPaired(A, B, []float64{0.2, 0, 0.05}, 500, 7)
+	assert.NoError(t, err)
+	assert.Len(t, res, 3)
+	assert.Equal(t, []float64{0, 0.05, 0.2}, []float64{res[0].RelativeSpeedupSampleAvsSampleB, res[1].RelativeSpeedupSampleAvsSampleB, res[2].RelativeSpeedupSampleAvsSampleB})
+	assert.Equal(t, 1.0, res[0].Confidence)
+	assert.Equal(t, 1.0, res[1].Confidence)
+	assert.Equal(t, 0.0, res[2].Confidence)
+
+	again, _ := CompareSamplesPaired(A, B, []float64{0.2, 0, 0.05}, 500, 7)
+	assert.Equal(t, res, again, "results must be reproducible for a fixed seed")
+
+	res, err = CompareSamplesPaired(A, B, nil, 0, 7)
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.True(t, math.IsNaN(res[0].Confidence))
+
+	_, err = CompareSamplesPaired(A, B[1:], nil, 100, 7)
+	assert.ErrorIs(t, err, ErrUnpairedSamples)
+	_, err = CompareSamplesPaired(A[:5], B[:5], nil, 100, 7)
+	assert.ErrorIs(t, err, ErrTooFewDataPoints)
+}
+
+func TestCompareSamplesWithOptionsAssumePaired(t *testing.T) {
+	rng := NewDPRNG(21)
+	const n = 40
+	A := make([]float64, n)
+	B := make([]float64, n)
+	for i := range n {
+		size := 100 + 900*rng.Float64()
+		B[i] = size
+		A[i] = 0.95 * size * (1 + 0.01*NormalQuantile(rng.Float64OpenOpen()))
+	}
+	gains := []float64{0.03, 0.06}
+
+	want, err := CompareSamplesPaired(A, B, slices.Clone(gains), 1000, 42)
+	if !assert.NoError(t, err) {
+		return
+	}
+	opts := CompareOptions{Resamples: 1000, Seed: 42, AssumePairedIfEqualLength: true}
+	got, err := CompareSamplesWithOptions(A, B, slices.Clone(gains), opts)
+	if assert.NoError(t, err) {
+		assert.Equal(t, want, got, "equal lengths must be routed to the paired bootstrap")
+	}
+
+	// the other options are honored by the paired path
+	opts.IncludeZeroThreshold = true
+	opts.DeltaFunc = AbsoluteDelta
+	got, err = CompareSamplesWithOptions(A, B, slices.Clone(gains), opts)
+	if assert.NoError(t, err) && assert.Len(t, got, 3) {
+		assert.Equal(t, 0.0, got[0].RelativeSpeedupSampleAvsSampleB)
+		assert.Equal(t, 1.0, got[0].Confidence)
+		assert.Equal(t, EstimatorPairedMedian, got[0].Estimator)
+		assert.Greater(t, got[0].ObservedDelta, 1.0, "absolute per-pair differences are in the units of the data")
+	}
+
+	// inputs of different length are compared unpaired
+	opts = CompareOptions{Resamples: 1000, Seed: 42, AssumePairedIfEqualLength: true}
+	got, err = CompareSamplesWithOptions(A[:n-1], B, slices.Clone(gains), opts)
+	if assert.NoError(t, err) {
+		opts.AssumePairedIfEqualLength = false
+		unpaired, _ := CompareSamplesWithOptions(A[:n-1], B, slices.Clone(gains), opts)
+		assert.Equal(t, unpaired, got)
+		assert.Equal(t, EstimatorMedian, got[0].Estimator)
+	}
+
+	_, err = CompareSamplesWithOptions(A, B, gains, CompareOptions{AssumePairedIfEqualLength: true, QuantizationStep: 1})
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+	_, err = NewComparator(B, CompareOptions{AssumePairedIfEqualLength: true})
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+}
@@ -0,0 +1,115 @@
+package rtcompare
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// CompareSamplesPermutation is an alternative to CompareSamples based on a permutation test instead of the
+// bootstrap. For each threshold t it tests the null hypothesis "A is faster than B by exactly t", i.e.
+// median(A) = (1-t)·median(B), against the alternative "A is faster by more than t":
+//
+//  1. The values of A are scaled by 1/(1-t), so that under the null hypothesis A and B come from the same
+//     distribution and their labels are exchangeable.
+//  2. In each of `permutations` rounds, the pooled values are randomly relabeled (shuffled and split into
+//     groups of len(A) and len(B) values), and the relative speedup 1 - median(A')/median(B') of the
+//     relabeled groups is computed.
+//  3. The reported Confidence is the fraction of rounds whose speedup is strictly below the observed speedup
+//     of the scaled A over B.
+//
+// 1 - Confidence is thus the one-sided permutation p-value for the threshold: reject "A is faster by at most
+// t" at level alpha if 1 - Confidence <= alpha. Like a bootstrap confidence, the value increases with the
+// evidence that A is faster by more than t, so both can be read the same way, but they answer different
+// questions: the bootstrap confidence estimates how probable a speedup of at least t is, given the sampling
+// variability of each input separately; the permutation test only asks how surprising the observed speedup
+// would be if the true speedup were exactly t. It assumes little beyond exchangeability, namely that A and B
+// differ only by a scale factor (e.g. A takes 20% less time across the whole distribution), and is exact
+// for any sample size in that case. Thresholds t >= 1 (and NaN) cannot be scaled and yield math.NaN().
+//
+// `permutations` and `seed` have the same meaning as `resamples` and `prngSeed` in BootstrapConfidence;
+// for permutations == 0 all confidences are math.NaN(). The ObservedDelta of the results is the unscaled
+// 1 - median(A)/median(B). An error wrapping ErrTooFewDataPoints is returned if either input contains fewer
+// than MinimumDataPoints values. The inputs are not modified.
+func CompareSamplesPermutation(A, B []float64, relativeGains []float64, permutations, seed uint64) (result []RTcomparisonResult, err error) {
+	if uint64(len(A)) < MinimumDataPoints || uint64(len(B)) < MinimumDataPoints {
+		return []RTcomparisonResult{}, fmt.Errorf("%w: need at least %d measurements for each input", ErrTooFewDataPoints, MinimumDataPoints)
+	}
+	if len(relativeGains) == 0 {
+		relativeGains = []float64{0.0}
+	}
+	relativeGains = slices.Clone(relativeGains)
+	slices.Sort(relativeGains)
+
+	medA := QuickMedian(slices.Clone(A))
+	medB := QuickMedian(slices.Clone(B))
+	scales := make([]float64, len(relativeGains))
+	observed := make([]float64, len(relativeGains))
+	for k, t := range relativeGains {
+		scales[k] = 1 / (1 - t)
+		observed[k] = relativeDelta(medA*scales[k], medB)
+	}
+
+	var rng RandSource
+	if seed != 0 {
+		d := NewDPRNG(seed)
+		rng = &d
+	} else {
+		c := NewCPRNG(8192)
+		defer c.Release()
+		rng = c
+	}
+
+	nA := len(A)
+	idx := make([]int, nA+len(B))
+	for i := range idx {
+		idx[i] = i
+	}
+	groupA := make([]float64, nA)
+	groupB := make([]float64, len(B))
+	counts := make([]uint64, len(relativeGains))
+	for range permutations {
+		// Fisher-Yates shuffle of the labels, shared by all thresholds
+		for i := len(idx) - 1; i > 0; i-- {
+			j := uint64n(rng, uint64(i+1))
+			idx[i], idx[j] = idx[j], idx[i]
+		}
+		for k, t := range relativeGains {
+			if !(t < 1) {
+				continue
+			}
+			for i, src := range idx {
+				v := 0.0
+				if src < nA {
+					v = A[src] * scales[k]
+				} else {
+					v = B[src-nA]
+				}
+				if i < nA {
+					groupA[i] = v
+				} else {
+					groupB[i-nA] = v
+				}
+			}
+			if relativeDelta(QuickMedian(groupA), QuickMedian(groupB)) < observed[k] {
+				counts[k]++
+			}
+		}
+	}
+
+	observedDelta := relativeDelta(medA, medB)
+	for k, t := range relativeGains {
+		confidence := math.NaN()
+		if permutations > 0 && t < 1 {
+			confidence = float64(counts[k]) / float64(permutations)
+		}
+		result = append(result, RTcomparisonResult{
+			RelativeSpeedupSampleAvsSampleB: t,
+			Confidence:                      confidence,
+			Estimator:                       EstimatorMedian,
+			ObservedDelta:                   observedDelta,
+			ConfidenceLowerBound:            wilsonLowerBound(confidence, permutations),
+		})
+	}
+	return result, nil
+}
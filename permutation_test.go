@@ -0,0 +1,60 @@
+package rtcompare
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareSamplesPermutation(t *testing.T) {
+	rng := NewDPRNG(17)
+	A := SyntheticSamples(40, 80, 0, 4, &rng) // 20% faster than B, with proportional noise
+	B := SyntheticSamples(40, 100, 0, 5, &rng)
+	gains := []float64{0.4, 0, 1, 0.2}
+	origA, origGains := slices.Clone(A), slices.Clone(gains)
+
+	res, err := CompareSamplesPermutation(A, B, gains, 2000, 42)
+	assert.NoError(t, err)
+	assert.Equal(t, origA, A, "inputs must not be modified")
+	assert.Equal(t, origGains, gains, "gains must not be modified")
+	if !assert.Len(t, res, 4) {
+		return
+	}
+	assert.Equal(t, []float64{0, 0.2, 0.4, 1}, []float64{res[0].RelativeSpeedupSampleAvsSampleB, res[1].RelativeSpeedupSampleAvsSampleB, res[2].RelativeSpeedupSampleAvsSampleB, res[3].RelativeSpeedupSampleAvsSampleB})
+	assert.Greater(t, res[0].Confidence, 0.99, "p-value for 'not faster' must be tiny")
+	assert.True(t, res[1].Confidence > 0.01 && res[1].Confidence < 0.99, "the true speedup must not be rejected, got %v", res[1].Confidence)
+	assert.Less(t, res[2].Confidence, 0.01)
+	assert.True(t, math.IsNaN(res[3].Confidence), "threshold 1 cannot be tested")
+	assert.InDelta(t, 1-Median(A)/Median(B), res[0].ObservedDelta, 1e-12)
+
+	again, _ := CompareSamplesPermutation(A, B, gains, 2000, 42)
+	assert.Equal(t, res[:3], again[:3], "results must be reproducible for a fixed seed")
+
+	res, err = CompareSamplesPermutation(A, B, nil, 0, 1)
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.True(t, math.IsNaN(res[0].Confidence))
+
+	_, err = CompareSamplesPermutation(A[:5], B, nil, 100, 1)
+	assert.ErrorIs(t, err, ErrTooFewDataPoints)
+}
+
+func TestCompareSamplesPermutationNullCalibration(t *testing.T) {
+	// with identically distributed inputs, the p-value 1 - Confidence is approximately uniform,
+	// so it falls below 0.1 in about 10% of the trials
+	const trials = 200
+	rejections := 0
+	for i := range trials {
+		rng := NewDPRNG(uint64(1000 + i))
+		A := SyntheticSamples(21, 100, 0, 10, &rng)
+		B := SyntheticSamples(21, 100, 0, 10, &rng)
+		res, err := CompareSamplesPermutation(A, B, nil, 200, uint64(i+1))
+		assert.NoError(t, err)
+		if 1-res[0].Confidence <= 0.1 {
+			rejections++
+		}
+	}
+	assert.InDelta(t, 0.1, float64(rejections)/trials, 0.06)
+}
@@ -0,0 +1,66 @@
+package rtcompare
+
+// Dithering of quantized measurements (CompareOptions.QuantizationStep).
+//
+// A timer with a resolution of step ticks reports a value on the grid k·step instead of the true value.
+// If the true values are spread over many ticks, this rounding is negligible. If they span only a few
+// ticks (e.g. per-op times derived from few inner loops, see GetSampleTimePrecision), many measurements
+// are identical, the median of every bootstrap sample snaps to one of a handful of grid values, and the
+// bootstrap distribution of the delta degenerates to a few spikes. Confidences then jump between
+// thresholds instead of varying smoothly, and replicates with equal medians (delta exactly 0) are
+// over-represented, which biases the confidence for threshold 0 in particular.
+//
+// Adding independent uniform noise U in [-step/2, step/2) to a rounded value Q(x) undoes the rounding
+// in distribution: if the true value is equally likely to lie anywhere within its tick, which holds
+// when the phase of the measured interval relative to the timer ticks is random, Q(x) + U has the same
+// distribution as x. The medians of the dithered bootstrap samples are therefore continuous and estimate
+// the median of the unquantized measurements rather than a grid value. Dithering does not add information;
+// it widens the bootstrap distribution by the uncertainty that the quantization actually introduces.
+//
+// The dither assumes a rounding timer. A truncating timer (which reports the start of the tick) biases both
+// inputs by -step/2; add step/2 to the measurements first if the absolute level matters, e.g. for relative
+// speedups of values only a few ticks long.
+
+// ditherSeed derives the seed of the dither noise for a bootstrap sample from the seed of the sample.
+// Zero is kept, so the noise of a CPRNG-drawn sample comes from a randomly seeded DPRNG.
+func ditherSeed(sampleSeed uint64) uint64 {
+	if sampleSeed == 0 {
+		return 0
+	}
+	return splitmix64(^sampleSeed)
+}
+
+// ditheredMedian adds uniform noise in [-step/2, step/2) to every element of sample in place, drawn from a
+// DPRNG seeded with ditherSeed(sampleSeed), and returns the median of the result. For step 0, sample is not
+// modified and its plain median is returned.
+func ditheredMedian(sample []float64, step float64, sampleSeed uint64) float64 {
+	if step > 0 {
+		rng := NewDPRNG(ditherSeed(sampleSeed))
+		for i := range sample {
+			sample[i] += step * (rng.Float64() - 0.5)
+		}
+	}
+	return medianAsFloat(sample)
+}
+
+// ditheredBootstrapDeltas is bootstrapDeltas with every bootstrap sample dithered by ditheredMedian.
+func ditheredBootstrapDeltas(A, B []float64, resamples uint64, prngSeed uint64, delta DeltaFunc, step float64) []float64 {
+	deltas := make([]float64, resamples)
+	sampleA, sampleB := make([]float64, len(A)), make([]float64, len(B))
+	for i := range resamples {
+		seedA, seedB := replicateSeeds(prngSeed, i)
+		medA := ditheredMedian(bootstrapSampleInto(sampleA, A, seedA), step, seedA)
+		medB := ditheredMedian(bootstrapSampleInto(sampleB, B, seedB), step, seedB)
+		deltas[i] = delta(medA, medB)
+	}
+	return deltas
+}
+
+// floatSamples converts xs to float64, e.g. int64 nanoseconds that are dithered.
+func floatSamples[T number](xs []T) []float64 {
+	result := make([]float64, len(xs))
+	for i, x := range xs {
+		result[i] = float64(x)
+	}
+	return result
+}
@@ -0,0 +1,89 @@
+package rtcompare
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDitherSeed(t *testing.T) {
+	assert.Equal(t, uint64(0), ditherSeed(0), "zero must stay zero to keep the noise random")
+	for _, seed := range []uint64{1, 2, 42, ^uint64(0)} {
+		assert.Equal(t, ditherSeed(seed), ditherSeed(seed))
+		assert.NotEqual(t, seed, ditherSeed(seed), "the noise must not reuse the stream of the sample")
+		assert.NotEqual(t, uint64(0), ditherSeed(seed))
+	}
+	assert.NotEqual(t, ditherSeed(1), ditherSeed(2))
+}
+
+func TestDitheredMedianZeroStep(t *testing.T) {
+	sample := []float64{3, 1, 4, 1, 5, 9, 2, 6, 5}
+	sorted := slices.Sorted(slices.Values(sample))
+	median := ditheredMedian(sample, 0, 7)
+	assert.Equal(t, Median(slices.Clone(sorted)), median)
+	slices.Sort(sample)
+	assert.Equal(t, sorted, sample, "step 0 must not change the values")
+}
+
+func TestDitheredMedianNoise(t *testing.T) {
+	const step = 10.0
+	orig := slices.Repeat([]float64{100, 110, 120}, 17)
+
+	// the median reorders the sample, so check the range of the noise on a constant sample
+	constant := slices.Repeat([]float64{100}, 51)
+	ditheredMedian(constant, step, 5)
+	for _, x := range constant {
+		assert.GreaterOrEqual(t, x, 100-step/2)
+		assert.Less(t, x, 100+step/2)
+	}
+	assert.Len(t, slices.Compact(slices.Sorted(slices.Values(constant))), 51, "every value must get its own noise")
+
+	sample := slices.Clone(orig)
+	median := ditheredMedian(sample, step, 5)
+	assert.Equal(t, median, Median(slices.Clone(sample)))
+	assert.NotEqual(t, 110.0, median, "the dithered median must leave the grid")
+
+	again := slices.Clone(orig)
+	assert.Equal(t, median, ditheredMedian(again, step, 5), "the noise must be deterministic per seed")
+	assert.NotEqual(t, median, ditheredMedian(slices.Clone(orig), step, 6))
+
+	// the median of a sample that spans only three ticks becomes continuous instead of a grid value
+	medians := make(map[float64]bool)
+	for seed := range uint64(100) {
+		m := ditheredMedian(slices.Clone(orig), step, seed+1)
+		assert.InDelta(t, 110, m, step/2)
+		medians[m] = true
+	}
+	assert.Len(t, medians, 100)
+}
+
+func TestDitheredBootstrapDeltas(t *testing.T) {
+	rng := NewDPRNG(3)
+	A := make([]float64, 31)
+	B := make([]float64, 41)
+	for i := range A {
+		A[i] = float64(90 + 10*rng.UInt32N(3))
+	}
+	for i := range B {
+		B[i] = float64(100 + 10*rng.UInt32N(3))
+	}
+	origA, origB := slices.Clone(A), slices.Clone(B)
+
+	plain := bootstrapDeltas(A, B, 500, 11, relativeDelta)
+	assert.Equal(t, plain, ditheredBootstrapDeltas(A, B, 500, 11, relativeDelta, 0), "step 0 must match bootstrapDeltas")
+
+	dithered := ditheredBootstrapDeltas(A, B, 500, 11, relativeDelta, 10)
+	assert.Equal(t, origA, A, "A must not be modified")
+	assert.Equal(t, origB, B, "B must not be modified")
+	assert.Equal(t, dithered, ditheredBootstrapDeltas(A, B, 500, 11, relativeDelta, 10))
+	distinct := func(xs []float64) int { return len(slices.Compact(slices.Sorted(slices.Values(xs)))) }
+	assert.Less(t, distinct(plain), 20, "undithered deltas of quantized inputs take only a few values")
+	assert.Greater(t, distinct(dithered), 490, "dithered deltas must be (almost) continuous")
+}
+
+func TestFloatSamples(t *testing.T) {
+	assert.Equal(t, []float64{1, -2, 300}, floatSamples([]int64{1, -2, 300}))
+	assert.Equal(t, []float64{0.5}, floatSamples([]float64{0.5}))
+	assert.Empty(t, floatSamples([]int64(nil)))
+}
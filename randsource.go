@@ -0,0 +1,45 @@
+package rtcompare
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// RandSource is the minimal interface shared by the random number generators of this package.
+// Both *DPRNG and *CPRNG implement it. Use a *DPRNG for reproducible results and a *CPRNG when
+// unpredictability is required.
+type RandSource interface {
+	// Uint64 returns a uniformly distributed uint64.
+	Uint64() uint64
+}
+
+// uint64n returns a uniformly distributed uint64 in the half-open interval [0,n) drawn from src.
+// It uses Lemire's multiply-and-shift reduction with rejection, so the result is unbiased.
+// For n=0 it returns 0.
+func uint64n(src RandSource, n uint64) uint64 {
+	if n == 0 {
+		return 0
+	}
+	hi, lo := bits.Mul64(src.Uint64(), n)
+	if lo < n {
+		thresh := -n % n
+		for lo < thresh {
+			hi, lo = bits.Mul64(src.Uint64(), n)
+		}
+	}
+	return hi
+}
+
+// uint64Range returns a uniformly distributed uint64 in the closed interval [min,max] drawn from src.
+// If the interval covers the full uint64 range, max-min+1 wraps to 0 and a raw src.Uint64() is returned.
+// It panics if min > max.
+func uint64Range(src RandSource, min, max uint64) uint64 {
+	if min > max {
+		panic(fmt.Sprintf("invalid range: min %d > max %d", min, max))
+	}
+	span := max - min + 1
+	if span == 0 {
+		return src.Uint64()
+	}
+	return min + uint64n(src, span)
+}
@@ -0,0 +1,24 @@
+package rtcompare
+
+import "testing"
+
+func TestUint64nBounds(t *testing.T) {
+	rng := NewDPRNG(0x1234567890ABCDEF)
+	cases := []uint64{1, 2, 3, 10, 1 << 32, 1<<63 + 1, ^uint64(0)}
+	for _, n := range cases {
+		for range 10_000 {
+			if v := uint64n(&rng, n); v >= n {
+				t.Fatalf("uint64n(%d) = %d; out of range", n, v)
+			}
+		}
+	}
+	if v := uint64n(&rng, 0); v != 0 {
+		t.Fatalf("uint64n(0) = %d; want 0", v)
+	}
+}
+
+func TestRandSourceImplementations(t *testing.T) {
+	rng := NewDPRNG(1)
+	var _ RandSource = &rng
+	var _ RandSource = NewCPRNG(64)
+}
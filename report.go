@@ -0,0 +1,186 @@
+package rtcompare
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// UnitMismatchRatio is the ratio between the medians of A and B above which CompareSamplesReport warns about
+// a likely unit mismatch, e.g. one input in microseconds and the other in nanoseconds.
+const UnitMismatchRatio = 1000
+
+// zeroVarianceAdvice completes the warnings of CompareSamplesReport about inputs with zero variance.
+const zeroVarianceAdvice = "Identical measurements usually mean that the timer resolution is too coarse for the measured code: " +
+	"time more inner loops per sample (see CalibrateInnerLoops) or collect more samples."
+
+// Report is the result of CompareSamplesReport: the per-threshold results of CompareSamples together with the
+// parameters and summary statistics needed to interpret them, e.g. as a JSON artifact of a CI run.
+type Report struct {
+	// SizeA and SizeB are the number of measurements of A and B.
+	SizeA int `json:"sizeA"`
+	SizeB int `json:"sizeB"`
+	// MedianA and MedianB are the medians of the full samples (see Median).
+	MedianA float64 `json:"medianA"`
+	MedianB float64 `json:"medianB"`
+	// ObservedDelta is the observed relative speedup 1 - MedianA/MedianB, see RTcomparisonResult.ObservedDelta.
+	ObservedDelta float64 `json:"observedDelta"`
+	// Seed is the seed of the bootstrap; zero means that a CPRNG was used, i.e. the results are not reproducible.
+	Seed uint64 `json:"seed"`
+	// Resamples is the number of bootstrap resamples.
+	Resamples uint64 `json:"resamples"`
+	// Results holds one entry per requested threshold, as returned by CompareSamples.
+	Results []RTcomparisonResult `json:"results"`
+	// StableA and StableB are the verdicts of AssessStability for A and B. For an unstable input, Warnings
+	// states its coefficient of variation and outlier fraction.
+	StableA bool `json:"stableA"`
+	StableB bool `json:"stableB"`
+	// LabelA and LabelB describe A and B if the report was created by CompareLabeled, and are nil otherwise.
+	LabelA *SampleLabel `json:"labelA,omitempty"`
+	LabelB *SampleLabel `json:"labelB,omitempty"`
+	// Warnings lists diagnostics that do not invalidate the results but should be reviewed, e.g. the
+	// message of CheckEvidence.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// CompareSamplesReport runs CompareSamples and returns its results as a Report. A resamples value of zero
+// selects DefaultResamples. The report contains a warning if resamples is below MinRecommendedResamples, if
+// an input is not stable according to AssessStability, if CheckEvidence reports an error for the results, or
+// if the medians of A and B differ by more than a factor of UnitMismatchRatio. The latter is only a guard
+// against inputs in different units (e.g. µs and ns), which would otherwise yield a wildly wrong speedup
+// without any error; genuine differences of that size are possible, so it is not an error. Convert the
+// inputs to the same unit with ScaleSamples.
+//
+// The report also warns about inputs with zero variance, i.e. all measurements identical, which is plausible
+// for a very stable microbenchmark timed with a coarse timer. The bootstrap cannot estimate the noise of such
+// an input: if both inputs are constant, every replicate yields the observed delta and each confidence is
+// exactly 0 or 1 with nothing in between. This is not evidence of a certain result but of insufficient
+// resolution; time more inner loops per sample or collect more samples.
+//
+// Unlike CompareSamples, CompareSamplesReport rejects inputs with non-finite values (see ValidateSamples) and
+// non-finite thresholds with an error wrapping ErrNonFiniteValues, and inputs whose median is zero or negative
+// with an error wrapping ErrNonPositiveValues: the relative speedup 1 - MedianA/MedianB is meaningless for
+// such medians and would be ±Inf for a zero MedianB (see BootstrapConfidence). This guarantees that every
+// field of the report is finite and can be serialized with encoding/json, which fails on NaN and ±Inf. Use
+// CleanSamples to strip non-finite values first. Errors are otherwise as for CompareSamples.
+// The inputs are not modified.
+func CompareSamplesReport(measurementsA, measurementsB []float64, relativeGains []float64, resamples uint64) (Report, error) {
+	for _, xs := range [][]float64{measurementsA, measurementsB} {
+		if err := ValidateSamples(xs); err != nil {
+			return Report{}, err
+		}
+	}
+	for _, t := range relativeGains {
+		if math.IsNaN(t) || math.IsInf(t, 0) {
+			return Report{}, fmt.Errorf("%w: threshold %v", ErrNonFiniteValues, t)
+		}
+	}
+	medianA, medianB := QuickMedian(slices.Clone(measurementsA)), QuickMedian(slices.Clone(measurementsB))
+	for _, in := range []struct {
+		name   string
+		median float64
+	}{{"A", medianA}, {"B", medianB}} {
+		if !(in.median > 0) {
+			return Report{}, fmt.Errorf("%w: median of %s is %v, the relative speedup is undefined", ErrNonPositiveValues, in.name, in.median)
+		}
+	}
+	if resamples == 0 {
+		resamples = DefaultResamples
+	}
+	opts := defaultCompareOptions(resamples)
+	results, err := compareSamples(measurementsA, measurementsB, slices.Clone(relativeGains), opts)
+	if err != nil {
+		return Report{}, err
+	}
+	report := Report{
+		SizeA:         len(measurementsA),
+		SizeB:         len(measurementsB),
+		MedianA:       medianA,
+		MedianB:       medianB,
+		ObservedDelta: results[0].ObservedDelta,
+		Seed:          opts.Seed,
+		Resamples:     opts.Resamples,
+		Results:       results,
+	}
+	if resamples < MinRecommendedResamples {
+		// the standard error of an estimated proportion p is sqrt(p(1-p)/R), which is largest for p = 0.5
+		report.Warnings = append(report.Warnings, fmt.Sprintf("only %d resamples: confidences may be off by up to ±%.1f%% (one standard error)",
+			resamples, 50/math.Sqrt(float64(resamples))))
+	}
+	for _, in := range []struct {
+		name    string
+		samples []float64
+		stable  *bool
+	}{{"A", measurementsA, &report.StableA}, {"B", measurementsB, &report.StableB}} {
+		cv, outliers, stable := AssessStability(in.samples)
+		*in.stable = stable
+		if !stable {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%s is not stable: coefficient of variation %.1f%% (limit %.0f%%), %.1f%% outliers (limit %.0f%%); consider collecting more or cleaner data",
+				in.name, cv*100, StableMaxCV*100, outliers*100, StableMaxOutlierFraction*100))
+		}
+	}
+	constA, constB := isConstant(measurementsA), isConstant(measurementsB)
+	switch {
+	case constA && constB:
+		report.Warnings = append(report.Warnings, "A and B have zero variance; confidence is deterministic, not probabilistic: "+
+			"every bootstrap replicate yields the observed delta, so each confidence is 0 or 1. "+zeroVarianceAdvice)
+	case constA || constB:
+		name := "A"
+		if constB {
+			name = "B"
+		}
+		report.Warnings = append(report.Warnings, name+" has zero variance; its noise cannot be estimated, so the confidences only reflect the variation of the other input. "+
+			zeroVarianceAdvice)
+	}
+	if lo, hi := min(report.MedianA, report.MedianB), max(report.MedianA, report.MedianB); hi > lo*UnitMismatchRatio {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("medians differ by a factor of %.0f (%g vs. %g): check that A and B use the same unit, see ScaleSamples",
+			hi/lo, report.MedianA, report.MedianB))
+	}
+	if err := CheckEvidence(measurementsA, measurementsB, results); err != nil {
+		report.Warnings = append(report.Warnings, err.Error())
+	}
+	return report, nil
+}
+
+// SampleLabel describes a set of measurements, see LabeledSamples.
+type SampleLabel struct {
+	// Name identifies the measurements, e.g. the name of a benchmark or an implementation.
+	Name string `json:"name"`
+	// Unit is the unit of the measurements, e.g. "ns" or "bytes". Empty if unknown.
+	Unit string `json:"unit,omitempty"`
+	// CollectedAt is the time the measurements were taken. The zero value means unknown.
+	CollectedAt time.Time `json:"collectedAt"`
+}
+
+// LabeledSamples is a set of measurements together with the metadata needed to make serialized comparisons
+// self-describing.
+type LabeledSamples struct {
+	Name        string    `json:"name"`
+	Unit        string    `json:"unit,omitempty"`
+	Values      []float64 `json:"values"`
+	CollectedAt time.Time `json:"collectedAt"`
+}
+
+// Label returns the metadata of s without its values.
+func (s LabeledSamples) Label() SampleLabel {
+	return SampleLabel{Name: s.Name, Unit: s.Unit, CollectedAt: s.CollectedAt}
+}
+
+// CompareLabeled is a variant of CompareSamplesReport for labeled measurements: it compares a.Values with
+// b.Values and stores the labels of a and b in the LabelA and LabelB fields of the report. In addition to the
+// warnings of CompareSamplesReport, the report contains a warning if both units are set but differ.
+// Parameters and errors are as for CompareSamplesReport.
+func CompareLabeled(a, b LabeledSamples, relativeGains []float64, resamples uint64) (Report, error) {
+	report, err := CompareSamplesReport(a.Values, b.Values, relativeGains, resamples)
+	if err != nil {
+		return Report{}, err
+	}
+	labelA, labelB := a.Label(), b.Label()
+	report.LabelA, report.LabelB = &labelA, &labelB
+	if a.Unit != "" && b.Unit != "" && a.Unit != b.Unit {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%s is measured in %q but %s in %q: convert both to the same unit, see ScaleSamples",
+			a.Name, a.Unit, b.Name, b.Unit))
+	}
+	return report, nil
+}
@@ -0,0 +1,211 @@
+package rtcompare
+
+import (
+	"encoding/json"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareSamplesReport(t *testing.T) {
+	rng := NewDPRNG(5)
+	A := SyntheticSamples(51, 80, 0, 2, &rng)
+	B := SyntheticSamples(41, 100, 0, 2, &rng)
+	gains := []float64{0.3, 0, 0.1}
+	origGains := slices.Clone(gains)
+
+	report, err := CompareSamplesReport(A, B, gains, 2000)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, origGains, gains, "gains must not be modified")
+	assert.Equal(t, 51, report.SizeA)
+	assert.Equal(t, 41, report.SizeB)
+	assert.Equal(t, Median(slices.Clone(A)), report.MedianA)
+	assert.Equal(t, Median(slices.Clone(B)), report.MedianB)
+	assert.InDelta(t, 1-report.MedianA/report.MedianB, report.ObservedDelta, 1e-12)
+	assert.Equal(t, uint64(0), report.Seed)
+	assert.Equal(t, uint64(2000), report.Resamples)
+	if !assert.Len(t, report.Results, 3) {
+		return
+	}
+	assert.Equal(t, 0.0, report.Results[0].RelativeSpeedupSampleAvsSampleB)
+	assert.Equal(t, 1.0, report.Results[0].Confidence)
+	assert.Equal(t, 0.0, report.Results[2].Confidence)
+	assert.True(t, report.StableA)
+	assert.True(t, report.StableB)
+	assert.Empty(t, report.Warnings)
+
+	data, err := json.Marshal(report)
+	if !assert.NoError(t, err) {
+		return
+	}
+	var decoded Report
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, report, decoded)
+	assert.Contains(t, string(data), `"medianA":`)
+	assert.Contains(t, string(data), `"relativeSpeedup":0.1`)
+	assert.NotContains(t, string(data), "warnings")
+	assert.NotContains(t, string(data), "factorThreshold")
+
+	// few resamples and swapped inputs produce warnings
+	report, err = CompareSamplesReport(B, A, []float64{0.1}, 100)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, report.Warnings, 2)
+	assert.Contains(t, report.Warnings[0], "only 100 resamples")
+	assert.Contains(t, report.Warnings[1], ErrNoEvidenceOfSpeedup.Error())
+
+	// a noisy input is flagged
+	noisy := SyntheticSamples(51, 80, 0, 20, &rng)
+	report, err = CompareSamplesReport(noisy, B, nil, 1000)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.False(t, report.StableA)
+	assert.True(t, report.StableB)
+	if assert.Len(t, report.Warnings, 1) {
+		assert.Contains(t, report.Warnings[0], "A is not stable")
+	}
+
+	// inputs in µs and ns are flagged as a likely unit mismatch, unless they are scaled first
+	report, err = CompareSamplesReport(ScaleSamples(A, 1.0/1000), B, nil, 1000)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if assert.Len(t, report.Warnings, 1) {
+		assert.Contains(t, report.Warnings[0], "same unit")
+	}
+	report, err = CompareSamplesReport(ScaleSamples(ScaleSamples(A, 1.0/1000), 1000), B, nil, 1000)
+	assert.NoError(t, err)
+	assert.Empty(t, report.Warnings)
+
+	report, err = CompareSamplesReport(A, B, nil, 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, DefaultResamples, report.Resamples)
+	assert.Len(t, report.Results, 1)
+}
+
+func TestCompareSamplesReportErrors(t *testing.T) {
+	valid := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}
+	_, err := CompareSamplesReport(valid, append(slices.Clone(valid), math.NaN()), nil, 100)
+	assert.ErrorIs(t, err, ErrNonFiniteValues)
+	_, err = CompareSamplesReport(nil, valid, nil, 100)
+	assert.ErrorIs(t, err, ErrEmptySample)
+	_, err = CompareSamplesReport(valid[:5], valid, nil, 100)
+	assert.ErrorIs(t, err, ErrTooFewDataPoints)
+	_, err = CompareSamplesReport(valid, valid, []float64{0, math.Inf(1)}, 100)
+	assert.ErrorIs(t, err, ErrNonFiniteValues)
+}
+
+func TestCompareSamplesReportNonPositiveMedian(t *testing.T) {
+	// a zero median of B makes 1 - medianA/medianB -Inf, which encoding/json cannot serialize
+	A := []float64{1, 1, 1, 1, 1, 2, 1, 1, 1, 1, 1}
+	B := []float64{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}
+	results, err := CompareSamples(A, B, nil, 100)
+	if assert.NoError(t, err) {
+		_, err = json.Marshal(results)
+		assert.Error(t, err, "the plain results contain -Inf")
+	}
+
+	report, err := CompareSamplesReport(A, B, nil, 100)
+	assert.ErrorIs(t, err, ErrNonPositiveValues)
+	_, err = json.Marshal(report)
+	assert.NoError(t, err, "the zero Report of an error must be serializable")
+
+	_, err = CompareSamplesReport(B, A, nil, 100)
+	assert.ErrorIs(t, err, ErrNonPositiveValues)
+	negative := ScaleSamples(A, -1)
+	_, err = CompareSamplesReport(negative, A, nil, 100)
+	assert.ErrorIs(t, err, ErrNonPositiveValues)
+
+	// positive medians yield a finite, serializable report even if some values are zero
+	report, err = CompareSamplesReport(A, append(slices.Clone(A), 0), nil, 100)
+	if assert.NoError(t, err) {
+		_, err = json.Marshal(report)
+		assert.NoError(t, err)
+	}
+}
+
+func TestCompareLabeled(t *testing.T) {
+	rng := NewDPRNG(6)
+	collected := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	a := LabeledSamples{Name: "BenchmarkNew", Unit: "ns", Values: SyntheticSamples(31, 80, 0, 2, &rng), CollectedAt: collected}
+	b := LabeledSamples{Name: "BenchmarkOld", Unit: "ns", Values: SyntheticSamples(31, 100, 0, 2, &rng), CollectedAt: collected.Add(-time.Hour)}
+
+	report, err := CompareLabeled(a, b, []float64{0.1}, 1000)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.NotNil(t, report.LabelA) || !assert.NotNil(t, report.LabelB) {
+		return
+	}
+	assert.Equal(t, SampleLabel{Name: "BenchmarkNew", Unit: "ns", CollectedAt: collected}, *report.LabelA)
+	assert.Equal(t, b.Label(), *report.LabelB)
+	assert.Equal(t, 31, report.SizeA)
+	assert.Empty(t, report.Warnings)
+
+	data, err := json.Marshal(report)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Contains(t, string(data), `"labelA":{"name":"BenchmarkNew","unit":"ns","collectedAt":"2024-06-01T12:00:00Z"}`)
+	var decoded Report
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, report, decoded)
+
+	// unlabeled reports omit the labels
+	report, err = CompareSamplesReport(a.Values, b.Values, nil, 1000)
+	assert.NoError(t, err)
+	data, err = json.Marshal(report)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "label")
+
+	b.Unit = "µs"
+	report, err = CompareLabeled(a, b, nil, 1000)
+	assert.NoError(t, err)
+	if assert.Len(t, report.Warnings, 1) {
+		assert.Contains(t, report.Warnings[0], `BenchmarkNew is measured in "ns" but BenchmarkOld in "µs"`)
+	}
+
+	_, err = CompareLabeled(LabeledSamples{Name: "empty"}, b, nil, 1000)
+	assert.ErrorIs(t, err, ErrEmptySample)
+}
+
+func TestCompareSamplesReportZeroVariance(t *testing.T) {
+	constant := slices.Repeat([]float64{100}, 20)
+	faster := slices.Repeat([]float64{90}, 20)
+	rng := NewDPRNG(7)
+	noisy := SyntheticSamples(20, 100, 0, 1, &rng)
+
+	report, err := CompareSamplesReport(faster, constant, []float64{0.05}, 1000)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 1.0, report.Results[0].Confidence)
+	if assert.Len(t, report.Warnings, 1) {
+		assert.Contains(t, report.Warnings[0], "A and B have zero variance; confidence is deterministic, not probabilistic")
+		assert.Contains(t, report.Warnings[0], "CalibrateInnerLoops")
+	}
+
+	report, err = CompareSamplesReport(noisy, constant, nil, 1000)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if assert.NotEmpty(t, report.Warnings) {
+		assert.Contains(t, report.Warnings[0], "B has zero variance")
+	}
+	report, err = CompareSamplesReport(constant, noisy, nil, 1000)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if assert.NotEmpty(t, report.Warnings) {
+		assert.Contains(t, report.Warnings[0], "A has zero variance")
+	}
+}
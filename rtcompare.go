@@ -1,6 +1,7 @@
 package rtcompare
 
 import (
+	"cmp"
 	"fmt"
 	"math"
 	"slices"
@@ -11,12 +12,37 @@ import (
 // that the speedup of sample A over sample B meets or exceeds that threshold.
 type RTcomparisonResult struct {
 	// RelativeSpeedupSampleAvsSampleB is the relative speedup threshold that was evaluated.
-	RelativeSpeedupSampleAvsSampleB float64
+	RelativeSpeedupSampleAvsSampleB float64 `json:"relativeSpeedup"`
 	// Confidence is the estimated confidence (in [0,1]) that the relative speedup of sample A over sample B
 	// meets or exceeds RelativeSpeedupSampleAvsSampleB.
-	Confidence float64
+	Confidence float64 `json:"confidence"`
+	// Estimator names the statistic that was used to summarize each (resampled) sample
+	// when computing the relative speedup, so stored results remain self-documenting.
+	Estimator Estimator `json:"estimator"`
+	// ObservedDelta is the delta of the full, non-resampled samples, e.g. 1 - median(A)/median(B) for
+	// CompareSamples. It is the same for all results of one comparison and serves as the point estimate
+	// when reporting, e.g. "observed speedup 23%, confidence 97% for ≥ 10%".
+	ObservedDelta float64 `json:"observedDelta"`
+	// FactorThreshold is the multiplicative speedup factor (e.g. 2.0 for "A is at least 2× faster") that was
+	// requested from CompareSamplesByFactor; RelativeSpeedupSampleAvsSampleB is then F2T(FactorThreshold).
+	// It is zero for results of the other comparison functions.
+	FactorThreshold float64 `json:"factorThreshold,omitempty"`
+	// ConfidenceLowerBound accounts for the Monte Carlo error of Confidence, which is estimated from a finite
+	// number of resamples: it is the lower end of the 95% Wilson score interval of Confidence as a proportion
+	// of the resamples. Even accounting for the Monte Carlo error, the confidence is thus at least
+	// ConfidenceLowerBound (with 95% probability). It approaches Confidence as the number of resamples grows
+	// and is math.NaN() if Confidence is.
+	ConfidenceLowerBound float64 `json:"confidenceLowerBound"`
 }
 
+// Estimator names the statistic used by a comparison to summarize a sample of measurements.
+type Estimator string
+
+// EstimatorMedian denotes the sample median. This is the estimator used by CompareSamples.
+const EstimatorMedian Estimator = "median"
+
+// MinimumDataPoints is the number of measurements per input that CompareSamples requires at least.
+// CompareSamplesWithOptions allows a different minimum via CompareOptions.MinimumDataPoints.
 const MinimumDataPoints uint64 = 11
 
 // DefaultResamples is a sensible package-level default for bootstrap resamples.
@@ -26,6 +52,14 @@ const MinimumDataPoints uint64 = 11
 // confidence estimates.
 const DefaultResamples uint64 = 5_000
 
+// MinRecommendedResamples and MaxRecommendedResamples bound the result of RecommendResamples. The minimum
+// is the lower end of the range recommended in the note on BootstrapConfidence; the maximum keeps a single
+// comparison within seconds to minutes for typical sample sizes.
+const (
+	MinRecommendedResamples uint64 = 1_000
+	MaxRecommendedResamples uint64 = 10_000_000
+)
+
 // CompareSamples compares two sets of scalar measurements (for example: runtimes,
 // memory footprints, or other numeric metrics) and estimates the confidence that
 // values from `measurementsA` are smaller than those from `measurementsB` by at
@@ -49,6 +83,11 @@ const DefaultResamples uint64 = 5_000
 // For each requested relative gain the function reports the fraction of replicates
 // where `delta >= threshold` as the confidence.
 //
+// Note the inclusive comparison: a replicate whose delta equals the threshold meets it. In
+// particular, for threshold 0 replicates with identical medians (delta == 0) count as "A is smaller
+// than B", so equal samples yield a confidence of 1 for threshold 0. Use CompareSamplesWithOptions
+// with StrictGreater to count only `delta > threshold` ("equal is not faster").
+//
 // Parameters:
 //
 //   - measurementsA, measurementsB: samples of scalar measurements (float64). Prefer
@@ -76,27 +115,114 @@ const DefaultResamples uint64 = 5_000
 //
 // Returns a slice of RTcomparisonResult where each entry contains the requested
 // relative threshold and the corresponding confidence in [0,1]. If either input
-// contains fewer than `MinimumDataPoints` values an error is returned.
+// contains fewer than `MinimumDataPoints` values an error wrapping ErrTooFewDataPoints
+// is returned.
+//
+// Non-finite values (NaN, ±Inf) in the inputs are not rejected, but they distort
+// the medians and thereby silently lower the reported confidences: every replicate
+// that draws a NaN has a NaN median (see QuickMedian) and meets no threshold. Use
+// `ValidateSamples` to reject such inputs or `CleanSamples` to strip them first.
+//
+// If both inputs are constant, the bootstrap is skipped because its result is known in advance
+// (see CompareOptions.NoShortCircuit).
 func CompareSamples(measurementsA, measurementsB []float64, relativeGains []float64, resamples uint64) (result []RTcomparisonResult, err error) {
-	if uint64(len(measurementsA)) < MinimumDataPoints || uint64(len(measurementsB)) < MinimumDataPoints {
-		return []RTcomparisonResult{}, fmt.Errorf("not enough data points: need at least %d measurements for each input", MinimumDataPoints)
+	return compareSamples(measurementsA, measurementsB, relativeGains, defaultCompareOptions(resamples))
+}
+
+// CompareSamplesInt is the int64 counterpart of CompareSamples for measurements that are naturally
+// integers, such as nanosecond timings from DiffTimeStamps or allocated byte counts.
+//
+// The bootstrap resamples and medians are computed on the int64 values and only the two medians of a
+// replicate are converted to float64 to compute their ratio. This keeps the medians exact even for
+// values beyond 2^53 (about 9·10^15), where converting every measurement to float64 up front would
+// round them to the float64 mantissa precision. Parameters and results are as for CompareSamples.
+func CompareSamplesInt(measurementsA, measurementsB []int64, relativeGains []float64, resamples uint64) (result []RTcomparisonResult, err error) {
+	return compareSamples(measurementsA, measurementsB, relativeGains, defaultCompareOptions(resamples))
+}
+
+// compareSamples is the generic implementation of CompareSamples, CompareSamplesInt and CompareSamplesWithOptions.
+// All fields of opts are used as they are, i.e. defaults must have been applied by the caller.
+func compareSamples[T number](measurementsA, measurementsB []T, relativeGains []float64, opts CompareOptions) (result []RTcomparisonResult, err error) {
+	minimum := opts.MinimumDataPoints
+	if uint64(len(measurementsA)) < minimum || uint64(len(measurementsB)) < minimum {
+		return []RTcomparisonResult{}, fmt.Errorf("%w: need at least %d measurements for each input", ErrTooFewDataPoints, minimum)
 	}
+	delta := opts.DeltaFunc
 	if len(relativeGains) == 0 {
 		relativeGains = []float64{0.0}
 	}
+	relativeGains = withZeroThreshold(relativeGains, opts.IncludeZeroThreshold)
 
 	slices.Sort(relativeGains)
 
-	conf := BootstrapConfidence(measurementsA, measurementsB, relativeGains, resamples, 0)
+	// one median per input, negligible compared to the bootstrap
+	observed := delta(medianAsFloat(slices.Clone(measurementsA)), medianAsFloat(slices.Clone(measurementsB)))
+	var conf map[float64]float64
+	switch {
+	case opts.QuantizationStep > 0:
+		deltas := ditheredBootstrapDeltas(floatSamples(measurementsA), floatSamples(measurementsB), opts.Resamples, opts.Seed, delta, opts.QuantizationStep)
+		conf = thresholdConfidence(deltas, relativeGains, opts.StrictGreater)
+	case !opts.NoShortCircuit && opts.Resamples > 0 && isConstant(measurementsA) && isConstant(measurementsB):
+		conf = constantConfidence(observed, relativeGains, opts.StrictGreater)
+	default:
+		conf = bootstrapConfidenceDelta(measurementsA, measurementsB, relativeGains, opts.Resamples, opts.Seed, delta, opts.StrictGreater)
+	}
+	return medianResults(relativeGains, conf, observed, opts.Resamples), nil
+}
+
+// withZeroThreshold implements CompareOptions.IncludeZeroThreshold: if include is set and relativeGains does
+// not contain 0, it returns a copy of relativeGains with 0 appended, otherwise a plain copy. The result never
+// aliases relativeGains, so the callers may sort it without modifying the caller's slice.
+func withZeroThreshold(relativeGains []float64, include bool) []float64 {
+	gains := slices.Clone(relativeGains)
+	if include && !slices.Contains(gains, 0) {
+		gains = append(gains, 0)
+	}
+	return gains
+}
+
+// constantConfidence returns the confidences for two constant inputs: every resample of a constant sample is
+// the same constant sample, so every replicate yields the observed delta and the bootstrap would count either
+// all or none of the replicates.
+func constantConfidence(observed float64, relativeGains []float64, strict bool) map[float64]float64 {
+	conf := make(map[float64]float64, len(relativeGains))
+	for _, t := range relativeGains {
+		if observed > t || (!strict && observed == t) {
+			conf[t] = 1
+		} else {
+			conf[t] = 0
+		}
+	}
+	return conf
+}
 
+// medianResults builds one EstimatorMedian result per threshold from the confidences of `resamples` replicates.
+func medianResults(relativeGains []float64, conf map[float64]float64, observed float64, resamples uint64) (result []RTcomparisonResult) {
 	for _, t := range relativeGains {
 		r := RTcomparisonResult{
 			RelativeSpeedupSampleAvsSampleB: t,
 			Confidence:                      conf[t],
+			Estimator:                       EstimatorMedian,
+			ObservedDelta:                   observed,
+			ConfidenceLowerBound:            wilsonLowerBound(conf[t], resamples),
 		}
 		result = append(result, r)
 	}
-	return result, nil
+	return result
+}
+
+// isConstant reports whether all elements of xs are equal. It returns false for an empty slice and for
+// slices containing NaN.
+func isConstant[T number](xs []T) bool {
+	if len(xs) == 0 {
+		return false
+	}
+	for _, x := range xs[1:] {
+		if x != xs[0] {
+			return false
+		}
+	}
+	return xs[0] == xs[0] // NaN != NaN
 }
 
 // CompareRuntimesDefault calls CompareRuntimes using `DefaultResamples`.
@@ -106,11 +232,68 @@ func CompareSamplesDefault(measurementsA, measurementsB []float64, relativeGains
 	return CompareSamples(measurementsA, measurementsB, relativeGains, DefaultResamples)
 }
 
+// CompareSamplesByFactor is a variant of CompareSamples that takes the thresholds as multiplicative speedup
+// factors instead of relative gains, e.g. 2.0 for "A is at least 2× faster than B" or 0.9 for "A is at most
+// about 11% slower than B". Each factor is converted with F2T, and each result carries the requested factor in
+// FactorThreshold in addition to the converted threshold in RelativeSpeedupSampleAvsSampleB. The results are
+// sorted by ascending factor. If factors is nil or empty, the single factor 1 (is A faster at all?) is evaluated.
+// An error wrapping ErrInvalidFactor is returned if a factor is not positive or is NaN; otherwise parameters,
+// errors and results are as for CompareSamples. The factors slice is not modified.
+func CompareSamplesByFactor(measurementsA, measurementsB []float64, factors []float64, resamples uint64) (result []RTcomparisonResult, err error) {
+	if len(factors) == 0 {
+		factors = []float64{1}
+	}
+	sorted := slices.Clone(factors)
+	slices.Sort(sorted)
+	thresholds := make([]float64, len(sorted))
+	for i, f := range sorted {
+		if !(f > 0) {
+			return []RTcomparisonResult{}, fmt.Errorf("%w: %v", ErrInvalidFactor, f)
+		}
+		thresholds[i] = F2T(f)
+	}
+	result, err = CompareSamples(measurementsA, measurementsB, thresholds, resamples)
+	if err != nil {
+		return result, err
+	}
+	// F2T is monotonically increasing, so the sorted thresholds correspond to the sorted factors
+	for i := range result {
+		result[i].FactorThreshold = sorted[i]
+	}
+	return result, nil
+}
+
 // Deprecated: Use CompareSamples instead. This function is retained for backward compatibility.
 func CompareRuntimes(measurementsA, measurementsB []float64, relativeGains []float64, resamples uint64) (result []RTcomparisonResult, err error) {
 	return CompareSamples(measurementsA, measurementsB, relativeGains, resamples)
 }
 
+// CheckEvidence is an optional diagnostic for the results of CompareSamples. If every confidence in
+// results is zero and the observed relative speedup of the full samples,
+// 1 - median(measurementsA)/median(measurementsB), does not exceed the smallest requested threshold,
+// it returns an error wrapping ErrNoEvidenceOfSpeedup that states the observed speedup. Such results
+// are frequently misread as a bug; typical causes are thresholds that are too ambitious or swapped
+// inputs (A must be the candidate expected to be smaller/faster). Otherwise CheckEvidence returns nil.
+// The inputs are not modified.
+func CheckEvidence(measurementsA, measurementsB []float64, results []RTcomparisonResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+	smallest := math.Inf(1)
+	for _, r := range results {
+		if r.Confidence > 0 {
+			return nil
+		}
+		smallest = math.Min(smallest, r.RelativeSpeedupSampleAvsSampleB)
+	}
+	observed := relativeDelta(QuickMedian(slices.Clone(measurementsA)), QuickMedian(slices.Clone(measurementsB)))
+	if observed > smallest {
+		return nil
+	}
+	return fmt.Errorf("%w: observed speedup of A over B is %.2f%%, smallest threshold is %.2f%%; consider adding threshold 0 or checking the input order",
+		ErrNoEvidenceOfSpeedup, observed*100, smallest*100)
+}
+
 // bootstrapSample returns a bootstrap sample (sampling with replacement) drawn from xs.
 // The returned slice has the same length as xs and is populated by selecting random
 // indices into xs using a deterministic PRNG initialized with prngSeed via NewDPRNG.
@@ -124,9 +307,17 @@ func CompareRuntimes(measurementsA, measurementsB []float64, relativeGains []flo
 // This implementation uses a DPRNG from this package for reproducible sampling.
 // Provide a specific non-zero seed for reproducible results across multiple calls.
 // If prngSeed is zero, the function uses a CPRNG with cryptographic strength randomness.
-func bootstrapSample(xs []float64, prngSeed uint64) []float64 {
+func bootstrapSample[T any](xs []T, prngSeed uint64) []T {
+	return bootstrapSampleInto(make([]T, len(xs)), xs, prngSeed)
+}
+
+// bootstrapSampleInto is bootstrapSample writing into sample, which must have the length of xs, and
+// returning it. Loops over many replicates reuse one buffer per input this way instead of allocating a
+// new sample per replicate. This matters most for the small samples typical of benchmarks, where the
+// medians are cheap (see smallMedianCutoff) and the allocations are a noticeable part of the bootstrap
+// time (see BenchmarkBootstrapDeltasSmall).
+func bootstrapSampleInto[T any](sample, xs []T, prngSeed uint64) []T {
 	n := len(xs)
-	sample := make([]float64, n)
 	if n == 0 {
 		return sample
 	}
@@ -138,6 +329,7 @@ func bootstrapSample(xs []float64, prngSeed uint64) []float64 {
 		}
 	} else {
 		rng := NewCPRNG(8192)
+		defer rng.Release()
 		for i := range n {
 			sample[i] = xs[rng.Uint32N(uint32(n))]
 		}
@@ -190,10 +382,27 @@ func bootstrapSample(xs []float64, prngSeed uint64) []float64 {
 //	A map[float64]float64 where each key is a threshold from `thresholds` and the corresponding value is
 //	the estimated confidence in [0,1] that the relative speedup of A over B is at least that threshold.
 func BootstrapConfidence(A, B []float64, relativeGains []float64, resamples uint64, prngSeed uint64) (confidenceForThreshold map[float64]float64) {
+	return bootstrapConfidence(A, B, relativeGains, resamples, prngSeed)
+}
 
-	confidenceForThreshold = make(map[float64]float64, len(relativeGains))
+// bootstrapConfidence is the generic implementation of BootstrapConfidence.
+func bootstrapConfidence[T number](A, B []T, relativeGains []float64, resamples uint64, prngSeed uint64) (confidenceForThreshold map[float64]float64) {
+	return bootstrapConfidenceDelta(A, B, relativeGains, resamples, prngSeed, relativeDelta, false)
+}
 
-	if resamples == 0 {
+// bootstrapConfidenceDelta is like bootstrapConfidence but evaluates each replicate with delta
+// instead of the relative speedup. If strict is true, a replicate meets a threshold only if its
+// delta is strictly greater than the threshold.
+func bootstrapConfidenceDelta[T number](A, B []T, relativeGains []float64, resamples uint64, prngSeed uint64, delta DeltaFunc, strict bool) (confidenceForThreshold map[float64]float64) {
+	return thresholdConfidence(bootstrapDeltas(A, B, resamples, prngSeed, delta), relativeGains, strict)
+}
+
+// thresholdConfidence returns, per threshold, the fraction of deltas that meet it (exceed it if strict is
+// true). NaN deltas count as not meeting any threshold. If deltas is empty, every threshold maps to math.NaN().
+func thresholdConfidence(deltas []float64, relativeGains []float64, strict bool) map[float64]float64 {
+	confidenceForThreshold := make(map[float64]float64, len(relativeGains))
+
+	if len(deltas) == 0 {
 		for _, threshold := range relativeGains {
 			confidenceForThreshold[threshold] = math.NaN()
 		}
@@ -202,54 +411,191 @@ func BootstrapConfidence(A, B []float64, relativeGains []float64, resamples uint
 
 	counts := make(map[float64]uint32, len(relativeGains))
 
-	for i := uint64(0); i < resamples; i++ {
-		var seedA, seedB uint64
-		if prngSeed == 0 {
-			// Preserve any default/non-deterministic behavior of bootstrapSample when seed is zero.
-			seedA = 0
-			seedB = 0
-		} else {
-			// Derive iteration-specific, distinct seeds for A and B from the base seed.
-			iterSeed := prngSeed + i
-			seedA = iterSeed*2 + 1
-			seedB = iterSeed*2 + 2
+	for _, d := range deltas {
+		for _, threshold := range relativeGains {
+			if d > threshold || (!strict && d == threshold) {
+				counts[threshold]++
+			}
 		}
+	}
+
+	for _, threshold := range relativeGains {
+		confidenceForThreshold[threshold] = float64(counts[threshold]) / float64(len(deltas))
+	}
+	return confidenceForThreshold
+}
+
+// bootstrapDeltas performs `resamples` bootstrap replicates as described for BootstrapConfidence and
+// returns delta(median(A_sample), median(B_sample)) of each replicate, e.g. the relative speedup
+// 1 - median(A_sample)/median(B_sample) for relativeDelta.
+// Replicates with a NaN median yield a NaN delta.
+func bootstrapDeltas[T number](A, B []T, resamples uint64, prngSeed uint64, delta DeltaFunc) []float64 {
+	deltas := make([]float64, resamples)
+	sampleA, sampleB := make([]T, len(A)), make([]T, len(B))
+	for i := range resamples {
+		seedA, seedB := replicateSeeds(prngSeed, i)
+		bootstrapSampleInto(sampleA, A, seedA)
+		bootstrapSampleInto(sampleB, B, seedB)
+		deltas[i] = delta(medianAsFloat(sampleA), medianAsFloat(sampleB))
+	}
+	return deltas
+}
+
+// replicateSeeds derives the seeds for the bootstrap samples of A and B in replicate i from the base seed.
+// A base seed of zero yields zero seeds, preserving the non-deterministic behavior of bootstrapSample.
+//
+// The seeds are the outputs 2i+1 and 2i+2 of a splitmix64 generator started at prngSeed. Unlike a linear
+// derivation (e.g. (prngSeed+i)*2+1), which yields adjacent DPRNG seeds with similar early xorshift*
+// output and lets the seeds of different base seeds overlap, the splitmix64 finalizer spreads every
+// counter value over the full 64 bit range, so the streams of A and B and of all replicates are well separated.
+func replicateSeeds(prngSeed, i uint64) (seedA, seedB uint64) {
+	if prngSeed == 0 {
+		return 0, 0
+	}
+	return splitmix64(prngSeed + (2*i+1)*splitmix64Gamma), splitmix64(prngSeed + (2*i+2)*splitmix64Gamma)
+}
 
-		sampleA := bootstrapSample(A, seedA)
-		sampleB := bootstrapSample(B, seedB)
-		medA := QuickMedian(sampleA)
-		medB := QuickMedian(sampleB)
+// splitmix64Gamma is the increment of the splitmix64 generator (the golden ratio scaled to 64 bits).
+const splitmix64Gamma = uint64(0x9E3779B97F4A7C15)
+
+// splitmix64 returns the splitmix64 finalizer of x (see https://prng.di.unimi.it/splitmix64.c),
+// a bijective mixing function. Zero, which NewDPRNG would replace with a random seed, is mapped to 1.
+func splitmix64(x uint64) uint64 {
+	x = (x ^ (x >> 30)) * 0xBF58476D1CE4E5B9
+	x = (x ^ (x >> 27)) * 0x94D049BB133111EB
+	x ^= x >> 31
+	if x == 0 {
+		return 1
+	}
+	return x
+}
 
-		var delta float64
+// relativeDelta returns the relative speedup delta = 1 - medA/medB with the NaN, zero, infinity and
+// tiny-denominator handling documented for BootstrapConfidence.
+func relativeDelta(medA, medB float64) float64 {
+	// robust: guard NaN and avoid divide-by-zero / huge ratios for tiny medB
+	if math.IsNaN(medA) || math.IsNaN(medB) {
+		return math.NaN()
+	}
+	if (medA == 0 && medB == 0) || medA == medB || (math.IsInf(medA, -1) && math.IsInf(medB, -1)) || (math.IsInf(medA, 1) && math.IsInf(medB, 1)) {
+		return 0.0
+	}
+	// relative epsilon scaled to medB to avoid large distortion
+	rel := 1e-12
+	eps := math.Max(math.Abs(medB)*rel, math.SmallestNonzeroFloat64)
+	denom := medB
+	if math.Abs(medB) < eps {
+		// treat as effectively zero -> use eps as denominator
+		denom = eps
+	}
+	return 1.0 - medA/denom
+}
 
-		// robust: guard NaN and avoid divide-by-zero / huge ratios for tiny medB
-		if math.IsNaN(medA) || math.IsNaN(medB) {
-			delta = math.NaN()
-		} else if (medA == 0 && medB == 0) || medA == medB || (math.IsInf(medA, -1) && math.IsInf(medB, -1)) || (math.IsInf(medA, 1) && math.IsInf(medB, 1)) {
-			delta = 0.0
+// ConfidenceCurve evaluates the bootstrap confidence (as computed by BootstrapConfidence) at `points`
+// evenly spaced relative speedup thresholds from `from` to `to` (both inclusive), e.g. to plot a
+// smooth confidence-vs-threshold curve. The bootstrap distribution of deltas is computed only once
+// and then thresholded at every point, which is far cheaper than calling CompareSamples per point.
+//
+// Parameters `resamples` and `seed` have the same meaning as `resamples` and `prngSeed` in
+// BootstrapConfidence. For points == 1 the single threshold is `from`. Returns nil slices if points
+// is zero or negative. If resamples is zero, all confidences are math.NaN().
+func ConfidenceCurve(A, B []float64, from, to float64, points int, resamples, seed uint64) (thresholds, confidences []float64) {
+	if points <= 0 {
+		return nil, nil
+	}
+	thresholds = make([]float64, points)
+	for i := range thresholds {
+		if points == 1 {
+			thresholds[i] = from
 		} else {
-			// relative epsilon scaled to medB to avoid large distortion
-			rel := 1e-12
-			eps := math.Max(math.Abs(medB)*rel, math.SmallestNonzeroFloat64)
-			denom := medB
-			if math.Abs(medB) < eps {
-				// treat as effectively zero -> use eps as denominator
-				denom = eps
-			}
-			delta = 1.0 - medA/denom
+			thresholds[i] = from + float64(i)*(to-from)/float64(points-1)
 		}
+	}
+	confidences = make([]float64, points)
+	if resamples == 0 {
+		for i := range confidences {
+			confidences[i] = math.NaN()
+		}
+		return thresholds, confidences
+	}
 
-		for _, threshold := range relativeGains {
-			if delta >= threshold {
-				counts[threshold]++
+	// sort the non-NaN deltas once, so each threshold needs only a binary search
+	deltas := slices.DeleteFunc(bootstrapDeltas(A, B, resamples, seed, relativeDelta), math.IsNaN)
+	slices.Sort(deltas)
+	for i, threshold := range thresholds {
+		// index of the first delta >= threshold
+		idx, _ := slices.BinarySearchFunc(deltas, threshold, func(d, t float64) int {
+			if d < t {
+				return -1
 			}
+			return 1
+		})
+		confidences[i] = float64(len(deltas)-idx) / float64(resamples)
+	}
+	return thresholds, confidences
+}
+
+// ConfidenceGrid shows how the confidence grows with the sample size, e.g. to plot a "how many samples do I
+// need" heatmap from data already collected. For each size in sizes, it runs BootstrapConfidence on the
+// first `size` measurements of A and of B and stores the confidence of each threshold in relativeGains
+// (nil or empty selects the threshold 0) as result[size][threshold].
+//
+// Sizes below MinimumDataPoints or above the length of A or B are invalid and have no entry in the result,
+// so the sizes present in the result are the valid ones. Parameters `resamples` and `seed` have the same
+// meaning as `resamples` and `prngSeed` in BootstrapConfidence; every size uses the same seed. Prefixes
+// assume that the measurements were taken in an order unrelated to their values; shuffle them otherwise.
+// The inputs are not modified.
+func ConfidenceGrid(A, B []float64, relativeGains []float64, sizes []int, resamples, seed uint64) map[int]map[float64]float64 {
+	if len(relativeGains) == 0 {
+		relativeGains = []float64{0.0}
+	}
+	result := make(map[int]map[float64]float64, len(sizes))
+	for _, size := range sizes {
+		if size < int(MinimumDataPoints) || size > len(A) || size > len(B) {
+			continue
 		}
+		result[size] = bootstrapConfidence(A[:size], B[:size], relativeGains, resamples, seed)
 	}
+	return result
+}
+
+// BootstrapDeltas performs `resamples` bootstrap replicates exactly like BootstrapConfidence and returns the
+// relative speedup delta = 1 - median(A_sample)/median(B_sample) of each replicate, in replicate order.
+// Replicates with a NaN median yield a NaN delta. Parameters `resamples` and `seed` have the same meaning as
+// `resamples` and `prngSeed` in BootstrapConfidence. Use ConfidenceFromDeltas to evaluate thresholds on the
+// result without repeating the bootstrap. The inputs are not modified.
+func BootstrapDeltas(A, B []float64, resamples, seed uint64) []float64 {
+	return bootstrapDeltas(A, B, resamples, seed, relativeDelta)
+}
 
+// ConfidenceFromDeltas returns, for each threshold in relativeGains, the fraction of deltas that are greater
+// than or equal to the threshold. deltas are typically the result of BootstrapDeltas, but may come from any
+// source, e.g. a bootstrap run by another tool. This separates the expensive resampling from the cheap
+// thresholding, so thresholds can be explored interactively after a single bootstrap run.
+//
+// NaN deltas are skipped, i.e. the fraction is relative to the number of non-NaN deltas. Note that
+// BootstrapConfidence instead counts NaN replicates as not meeting any threshold, so both only agree if
+// there are no NaN deltas. If there are no non-NaN deltas, every threshold maps to math.NaN().
+// The deltas slice is not modified.
+func ConfidenceFromDeltas(deltas []float64, relativeGains []float64) map[float64]float64 {
+	result := make(map[float64]float64, len(relativeGains))
+	sorted := slices.DeleteFunc(slices.Clone(deltas), math.IsNaN)
+	slices.Sort(sorted)
 	for _, threshold := range relativeGains {
-		confidenceForThreshold[threshold] = float64(counts[threshold]) / float64(resamples)
+		if len(sorted) == 0 {
+			result[threshold] = math.NaN()
+			continue
+		}
+		// index of the first delta >= threshold
+		idx, _ := slices.BinarySearchFunc(sorted, threshold, func(d, t float64) int {
+			if d < t {
+				return -1
+			}
+			return 1
+		})
+		result[threshold] = float64(len(sorted)-idx) / float64(len(sorted))
 	}
-	return confidenceForThreshold
+	return result
 }
 
 // F2T (FactorToThreshold) converts a multiplicative speedup timesFaster (e.g. 3.0 => A is 3× faster)
@@ -260,3 +606,253 @@ func F2T(timesFaster float64) float64 {
 	}
 	return 1.0 - 1.0/timesFaster
 }
+
+// T2F (ThresholdToFactor) is the inverse of F2T: it converts a relative-reduction threshold or delta as used by
+// CompareSamples and BootstrapConfidence (e.g. 0.5) to the multiplicative speedup factor 1/(1-threshold)
+// (e.g. 2.0 => A is 2× faster). A threshold of 1 (A takes no time at all) yields +Inf; thresholds above 1 and
+// NaN yield math.NaN().
+func T2F(threshold float64) float64 {
+	if threshold > 1 || math.IsNaN(threshold) {
+		return math.NaN()
+	}
+	if threshold == 1 {
+		return math.Inf(1)
+	}
+	return 1.0 / (1.0 - threshold)
+}
+
+// SpeedupFactorCI estimates how many times faster A is than B, i.e. median(B)/median(A), together with a
+// bootstrap percentile confidence interval, e.g. "A is 1.8× faster (95% CI: 1.6×–2.0×)".
+//
+// The point estimate is computed from the medians of the full samples. For the interval, the function performs
+// `resamples` bootstrap replicates exactly like BootstrapConfidence (including its handling of NaN medians and
+// of medians of B that are zero or extremely small) and converts the relative speedup delta of each replicate to
+// a factor with T2F. lo and hi are the (1-confidence)/2 and 1-(1-confidence)/2 percentiles (see PercentileSorted)
+// of these factors; replicates with a NaN delta are ignored. Parameters `resamples` and `seed` have the same
+// meaning as `resamples` and `prngSeed` in BootstrapConfidence.
+//
+// confidence may be given as a fraction or as a percentage (0.95 or 95, see MeanConfidenceInterval).
+// Returns math.NaN() for all three values if A or B is empty, if resamples is zero, or if confidence is not
+// in the open interval (0, 1). The inputs are not modified.
+func SpeedupFactorCI(A, B []float64, confidence float64, resamples, seed uint64) (point, lo, hi float64) {
+	confidence, err := normalizeConfidence(confidence)
+	if len(A) == 0 || len(B) == 0 || resamples == 0 || err != nil || !(confidence > 0 && confidence < 1) {
+		return math.NaN(), math.NaN(), math.NaN()
+	}
+	point = T2F(relativeDelta(QuickMedian(slices.Clone(A)), QuickMedian(slices.Clone(B))))
+
+	// T2F is monotonically increasing, so the percentiles of the factors are the factors of the percentiles
+	deltas := slices.DeleteFunc(bootstrapDeltas(A, B, resamples, seed, relativeDelta), math.IsNaN)
+	slices.Sort(deltas)
+	alpha := 1 - confidence
+	lo = T2F(PercentileSorted(deltas, alpha/2*100))
+	hi = T2F(PercentileSorted(deltas, (1-alpha/2)*100))
+	return point, lo, hi
+}
+
+// RequiredSamples estimates how many measurements per implementation are needed so that CompareSamples
+// reports a confidence of at least targetConfidence for "A is faster than B" (threshold 0) if A is in fact
+// faster by the relative amount `effect` (e.g. 0.1 for 10%; negative values are treated like their absolute
+// value). Use it before an expensive benchmark run to avoid both under- and over-sampling.
+//
+// The estimate is a normal approximation based on the mean and variance of the baseline (as returned by
+// Statistics), assuming both implementations show the same spread. With s the sample standard deviation
+// and z = NormalQuantile(targetConfidence), the difference of two means of n values each has a standard
+// error of s*sqrt(2/n), and detecting a difference of effect*mean requires
+//
+//	n = 2 * (z * s / (effect * mean))² * π/2
+//
+// The factor π/2 accounts for the median being a less efficient estimator than the mean (its asymptotic
+// relative efficiency for normal data is 2/π). The result is rounded up and is at least MinimumDataPoints.
+// Runtime measurements are typically skewed, so treat the result as a lower bound and add a margin.
+//
+// targetConfidence may be given as a fraction or as a percentage (0.95 or 95, see MeanConfidenceInterval).
+// Returns 0 if baseline contains fewer than two values, if its mean is not positive, if effect is zero or
+// not finite, or if targetConfidence is not in the open interval (0.5, 1).
+func RequiredSamples(baseline []float64, effect float64, targetConfidence float64) int {
+	n := len(baseline)
+	targetConfidence, err := normalizeConfidence(targetConfidence)
+	if n < 2 || effect == 0 || math.IsNaN(effect) || math.IsInf(effect, 0) || err != nil || !(targetConfidence > 0.5 && targetConfidence < 1) {
+		return 0
+	}
+	mean, variance, _ := Statistics(baseline)
+	if !(mean > 0) {
+		return 0
+	}
+	sampleVariance := variance * float64(n) / float64(n-1)
+	z := NormalQuantile(targetConfidence)
+	delta := math.Abs(effect) * mean
+	required := math.Ceil(2 * z * z * sampleVariance / (delta * delta) * math.Pi / 2)
+	if required < float64(MinimumDataPoints) {
+		return int(MinimumDataPoints)
+	}
+	if required > math.MaxInt {
+		return math.MaxInt
+	}
+	return int(required)
+}
+
+// ProbabilitySuperiority returns the probability that a randomly drawn measurement of A is smaller
+// (faster) than a randomly drawn measurement of B: the fraction of all len(A)·len(B) pairs (a, b) with
+// a < b, where ties count as 0.5. This is the common-language effect size, the Mann-Whitney U statistic
+// of A divided by len(A)·len(B). 0.5 means no tendency, 1 means that every measurement of A is smaller
+// than every measurement of B. Unlike CompareSamples it needs no threshold and no estimator, and it is
+// invariant under any monotonic transformation of the measurements, e.g. a change of units.
+//
+// Both inputs are sorted (copies; the inputs are not modified) and merged, so the runtime is
+// O(n log n) instead of O(n²) for comparing all pairs. Returns math.NaN() if A or B is empty or
+// contains NaN.
+func ProbabilitySuperiority(A, B []float64) float64 {
+	if len(A) == 0 || len(B) == 0 || hasNaN(A) || hasNaN(B) {
+		return math.NaN()
+	}
+	sortedA := slices.Clone(A)
+	slices.Sort(sortedA)
+	sortedB := slices.Clone(B)
+	slices.Sort(sortedB)
+	// for each a (ascending), less and lessEq are the numbers of elements of B that are < a and <= a
+	var wins float64
+	less, lessEq := 0, 0
+	for _, a := range sortedA {
+		for less < len(sortedB) && sortedB[less] < a {
+			less++
+		}
+		lessEq = max(lessEq, less)
+		for lessEq < len(sortedB) && sortedB[lessEq] <= a {
+			lessEq++
+		}
+		wins += float64(len(sortedB)-lessEq) + 0.5*float64(lessEq-less)
+	}
+	return wins / (float64(len(sortedA)) * float64(len(sortedB)))
+}
+
+// StochasticDominance compares the empirical cumulative distribution functions (ECDFs) F_A and F_B of the
+// measurements. aDominatesB reports that A is stochastically smaller (faster) than B: F_A(x) >= F_B(x) for
+// every x, with strict inequality for at least one x, i.e. the ECDF of A lies entirely on or above that of B
+// and every quantile of A is at most the corresponding quantile of B. bDominatesA is the reverse. If neither
+// dominates, the ECDFs cross or are identical; crossoverPoints then lists, in ascending order, the
+// measurement values at which the sign of F_A - F_B flips, e.g. where A stops being faster and becomes
+// slower. Between two crossover points, one of A and B is faster at all quantiles of that range.
+//
+// This makes the rigorous statement "A is faster at every quantile" that a single number such as
+// CompareSamples or ProbabilitySuperiority cannot make. Note that it is a statement about the samples
+// without a confidence: with small samples, noise in the tails easily produces spurious crossings, so
+// use QuantileComparison to see how large the differences at the crossings are.
+//
+// Both inputs are sorted (copies; the inputs are not modified) and their ECDFs are compared at every
+// measurement value, where the ECDFs change. Returns false, false and nil if A or B is empty or contains NaN.
+func StochasticDominance(A, B []float64) (aDominatesB bool, bDominatesA bool, crossoverPoints []float64) {
+	if len(A) == 0 || len(B) == 0 || hasNaN(A) || hasNaN(B) {
+		return false, false, nil
+	}
+	sortedA := slices.Clone(A)
+	slices.Sort(sortedA)
+	sortedB := slices.Clone(B)
+	slices.Sort(sortedB)
+	aAbove, bAbove := false, false
+	lastSign := 0
+	i, j := 0, 0
+	for i < len(sortedA) || j < len(sortedB) {
+		x := math.Inf(1)
+		if i < len(sortedA) {
+			x = sortedA[i]
+		}
+		if j < len(sortedB) {
+			x = min(x, sortedB[j])
+		}
+		for i < len(sortedA) && sortedA[i] <= x {
+			i++
+		}
+		for j < len(sortedB) && sortedB[j] <= x {
+			j++
+		}
+		// sign of F_A(x) - F_B(x) = i/len(A) - j/len(B), compared exactly in integers
+		sign := cmp.Compare(i*len(sortedB), j*len(sortedA))
+		switch sign {
+		case 1:
+			aAbove = true
+		case -1:
+			bAbove = true
+		default:
+			continue
+		}
+		if lastSign != 0 && sign != lastSign {
+			crossoverPoints = append(crossoverPoints, x)
+		}
+		lastSign = sign
+	}
+	return aAbove && !bAbove, bAbove && !aAbove, crossoverPoints
+}
+
+// QuantileComparison compares A and B at several quantiles instead of a single median. For each q in
+// quantiles (a fraction in [0, 1], e.g. 0.5 for the median or 0.99 for P99) the result maps q to the
+// relative difference
+//
+//	1 - quantile_q(A)/quantile_q(B)
+//
+// computed with the index rule of Percentile (the element at index floor(q·n), without interpolation)
+// and the zero and infinity handling documented for BootstrapConfidence. As with CompareSamples, a
+// positive value means A is smaller (faster) than B at that quantile. This reveals patterns that a single
+// scalar collapses, e.g. A being faster at the median but slower at P99. Note that this is a point
+// estimate without a confidence; tail quantiles of small samples are particularly noisy.
+//
+// Quantiles outside [0, 1] map to math.NaN(), as do all quantiles if A or B is empty.
+// The inputs are not modified.
+func QuantileComparison(A, B []float64, quantiles []float64) map[float64]float64 {
+	result := make(map[float64]float64, len(quantiles))
+	sortedA := slices.Clone(A)
+	slices.Sort(sortedA)
+	sortedB := slices.Clone(B)
+	slices.Sort(sortedB)
+	for _, q := range quantiles {
+		qA := PercentileSorted(sortedA, q*100)
+		qB := PercentileSorted(sortedB, q*100)
+		result[q] = relativeDelta(qA, qB)
+	}
+	return result
+}
+
+// CompareToTarget is the one-sample counterpart of CompareSamples for comparisons against a fixed budget,
+// e.g. "the median latency must be under 5 ms". It performs `resamples` bootstrap replicates of `samples`
+// (drawn as for BootstrapConfidence) and returns the fraction of replicates whose median is strictly below
+// target. Parameters `resamples` and `seed` have the same meaning as `resamples` and `prngSeed` in
+// BootstrapConfidence.
+//
+// Returns math.NaN() if samples is empty, if resamples is zero, or if target is NaN. Replicates with a NaN
+// median do not count as below target. The input is not modified.
+func CompareToTarget(samples []float64, target float64, resamples, seed uint64) (confidenceUnder float64) {
+	if len(samples) == 0 || resamples == 0 || math.IsNaN(target) {
+		return math.NaN()
+	}
+	var under uint64
+	for i := range resamples {
+		s, _ := replicateSeeds(seed, i)
+		if QuickMedian(bootstrapSample(samples, s)) < target {
+			under++
+		}
+	}
+	return float64(under) / float64(resamples)
+}
+
+// RecommendResamples returns the number of bootstrap resamples needed for the Monte Carlo standard error
+// of a confidence estimate to be at most targetStdErr (e.g. 0.005 for ±0.5 percentage points). A confidence
+// is a proportion estimated from R replicates, so its standard error is sqrt(p(1-p)/R) and
+//
+//	R = p(1-p) / targetStdErr²
+//
+// where p is observedConfidence, typically read from a cheap pilot run of CompareSamples with few
+// resamples. Use the confidence of the threshold you are most interested in, or 0.5 for the worst case.
+// The result is rounded up and clamped to [MinRecommendedResamples, MaxRecommendedResamples]; the minimum
+// also applies to pilot confidences of exactly 0 or 1, which do not mean that the estimate is exact.
+// Returns 0 if observedConfidence is not in [0, 1] or if targetStdErr is not positive (or NaN).
+func RecommendResamples(observedConfidence float64, targetStdErr float64) uint64 {
+	if !(observedConfidence >= 0 && observedConfidence <= 1) || !(targetStdErr > 0) {
+		return 0
+	}
+	// the tolerance keeps results that are integral up to rounding errors, e.g. 0.99*0.01/0.001², from being rounded up
+	r := math.Ceil(observedConfidence*(1-observedConfidence)/(targetStdErr*targetStdErr) - 1e-9)
+	if r >= float64(MaxRecommendedResamples) {
+		return MaxRecommendedResamples
+	}
+	return max(uint64(r), MinRecommendedResamples)
+}
@@ -1,10 +1,12 @@
 package rtcompare
 
 import (
+	"errors"
+	"fmt"
 	"math"
-	"math/rand"
 	"reflect"
 	"slices"
+	"strings"
 	"testing"
 	"testing/quick"
 )
@@ -16,6 +18,12 @@ func TestCompareRuntimesTooFewData(t *testing.T) {
 	if err == nil {
 		t.Errorf("Expected error for too few data points, got nil")
 	}
+	if !errors.Is(err, ErrTooFewDataPoints) {
+		t.Errorf("Expected ErrTooFewDataPoints, got %v", err)
+	}
+	if err.Error() != "not enough data points: need at least 11 measurements for each input" {
+		t.Errorf("Unexpected error message: %q", err.Error())
+	}
 }
 
 func TestCompareRuntimesDefaultThreshold(t *testing.T) {
@@ -224,6 +232,7 @@ func TestBootstrapConfidenceRandomSeed(t *testing.T) {
 
 func TestBootstrapConfidenceRange(t *testing.T) {
 	// Property: Für beliebige Eingaben liegt conf[t] ∈ [0, 1]
+	seeds := NewDPRNG(98)
 	prop := func(A, B []float64) bool {
 		if len(A) == 0 || len(B) == 0 {
 			return true // skip invalid input
@@ -231,7 +240,7 @@ func TestBootstrapConfidenceRange(t *testing.T) {
 
 		thresholds := []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9}
 		reps := uint64(100)
-		seed := rand.Uint64()&0xFFFFFFFFFFFFFFE + 1 // avoid zero seed
+		seed := seeds.Uint64() | 1 // avoid zero seed
 
 		conf := BootstrapConfidence(A, B, thresholds, reps, seed)
 
@@ -247,7 +256,7 @@ func TestBootstrapConfidenceRange(t *testing.T) {
 
 	if err := quick.Check(prop, &quick.Config{
 		MaxCount: 10_000,
-		Rand:     rand.New(rand.NewSource(99)),
+		Rand:     QuickRand(99),
 	}); err != nil {
 		t.Error(err)
 	}
@@ -262,16 +271,11 @@ func TestBootstrapConfidenceMonotony(t *testing.T) {
 	x := 0.05     // let A be 5% faster
 	sigma := 15.0 // noise standard deviation
 	reps := uint64(10_000)
-	seed := uint64(0)
+	seed := uint64(0x5EED)
 
-	A := make([]float64, n)
-	B := make([]float64, n)
-	for i := range n {
-		valA := 100.0 + rand.NormFloat64()*sigma
-		valB := 100.0*(1+x) + rand.NormFloat64()*sigma
-		A[i] = valA
-		B[i] = valB
-	}
+	rng := NewDPRNG(0x1234567890ABCDEF)
+	A := SyntheticSamples(n, 100, 0, sigma, &rng)
+	B := SyntheticSamples(n, 100, x, sigma, &rng)
 
 	thresholds := []float64{x - 0.02, x - 0.01, x, x + 0.01, x + 0.02}
 	conf := BootstrapConfidence(A, B, thresholds, reps, seed)
@@ -627,3 +631,715 @@ func TestF2TEdgeCases(t *testing.T) {
 		}
 	})
 }
+
+func TestCompareSamplesReportsEstimator(t *testing.T) {
+	A := make([]float64, 11)
+	B := make([]float64, 11)
+	for i := range A {
+		A[i] = 100
+		B[i] = 120
+	}
+	results, err := CompareSamples(A, B, []float64{0.0, 0.1}, 100)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, r := range results {
+		if r.Estimator != EstimatorMedian {
+			t.Errorf("Expected estimator %q, got %q", EstimatorMedian, r.Estimator)
+		}
+	}
+}
+
+func TestCompareSamplesReportsObservedDelta(t *testing.T) {
+	A := []float64{80, 81, 79, 80, 82, 78, 80, 81, 79, 80, 90}
+	B := []float64{100, 101, 99, 100, 102, 98, 100, 101, 99, 100, 50}
+	orig := slices.Clone(A)
+	results, err := CompareSamples(A, B, []float64{0.0, 0.1, 0.5}, 100)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !slices.Equal(orig, A) {
+		t.Errorf("input must not be modified")
+	}
+	want := 1 - Median(A)/Median(B)
+	for _, r := range results {
+		if r.ObservedDelta != want {
+			t.Errorf("threshold %v: expected observed delta %v, got %v", r.RelativeSpeedupSampleAvsSampleB, want, r.ObservedDelta)
+		}
+	}
+
+	intResults, err := CompareSamplesInt([]int64{8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8}, []int64{10, 10, 10, 10, 10, 10, 10, 10, 10, 10, 10}, nil, 10)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if math.Abs(intResults[0].ObservedDelta-0.2) > 1e-12 {
+		t.Errorf("expected observed delta 0.2, got %v", intResults[0].ObservedDelta)
+	}
+}
+
+func TestConfidenceCurveMatchesBootstrapConfidence(t *testing.T) {
+	A := []float64{100, 101, 99, 98, 102, 103, 97, 104, 96, 105, 100}
+	B := []float64{120, 118, 122, 119, 121, 117, 123, 116, 124, 115, 99}
+	reps := uint64(2000)
+	seed := uint64(42)
+
+	thresholds, confidences := ConfidenceCurve(A, B, -0.1, 0.3, 41, reps, seed)
+	if len(thresholds) != 41 || len(confidences) != 41 {
+		t.Fatalf("expected 41 points, got %d thresholds and %d confidences", len(thresholds), len(confidences))
+	}
+	if thresholds[0] != -0.1 || math.Abs(thresholds[40]-0.3) > 1e-12 {
+		t.Fatalf("unexpected range of thresholds: %v .. %v", thresholds[0], thresholds[40])
+	}
+	conf := BootstrapConfidence(A, B, thresholds, reps, seed)
+	for i, th := range thresholds {
+		if confidences[i] != conf[th] {
+			t.Errorf("threshold %.3f: ConfidenceCurve=%v, BootstrapConfidence=%v", th, confidences[i], conf[th])
+		}
+		if i > 0 && confidences[i] > confidences[i-1] {
+			t.Errorf("confidence not decreasing at threshold %.3f", th)
+		}
+	}
+}
+
+func TestConfidenceCurveEdgeCases(t *testing.T) {
+	A := []float64{1, 2, 3}
+	B := []float64{2, 3, 4}
+
+	thresholds, confidences := ConfidenceCurve(A, B, 0, 1, 0, 10, 42)
+	if thresholds != nil || confidences != nil {
+		t.Errorf("expected nil slices for points == 0")
+	}
+
+	thresholds, confidences = ConfidenceCurve(A, B, 0.25, 1, 1, 10, 42)
+	if len(thresholds) != 1 || thresholds[0] != 0.25 || len(confidences) != 1 {
+		t.Errorf("expected a single threshold 0.25, got %v", thresholds)
+	}
+
+	_, confidences = ConfidenceCurve(A, B, 0, 1, 3, 0, 42)
+	for _, c := range confidences {
+		if !math.IsNaN(c) {
+			t.Errorf("expected NaN confidences for resamples == 0, got %v", c)
+		}
+	}
+
+	// NaN medians never meet a threshold
+	_, confidences = ConfidenceCurve([]float64{math.NaN()}, B, -1, 1, 3, 10, 42)
+	for _, c := range confidences {
+		if c != 0 {
+			t.Errorf("expected confidence 0 for NaN input, got %v", c)
+		}
+	}
+}
+
+func TestCheckEvidence(t *testing.T) {
+	A := make([]float64, 11)
+	B := make([]float64, 11)
+	for i := range A {
+		A[i] = 120
+		B[i] = 100
+	}
+	// A is slower than B: no threshold >= 0 can be met
+	results, err := CompareSamples(A, B, []float64{0.0, 0.1}, 100)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	err = CheckEvidence(A, B, results)
+	if !errors.Is(err, ErrNoEvidenceOfSpeedup) {
+		t.Fatalf("expected ErrNoEvidenceOfSpeedup, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "-20.00%") {
+		t.Errorf("expected observed speedup in error message, got %q", err.Error())
+	}
+
+	// swapped inputs: strong evidence
+	results, err = CompareSamples(B, A, []float64{0.0, 0.1}, 100)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := CheckEvidence(B, A, results); err != nil {
+		t.Errorf("expected no diagnostic, got %v", err)
+	}
+
+	// all confidences zero, but the observed speedup exceeds the smallest threshold
+	zero := []RTcomparisonResult{{RelativeSpeedupSampleAvsSampleB: 0.1}}
+	if err := CheckEvidence(B, A, zero); err != nil {
+		t.Errorf("expected no diagnostic, got %v", err)
+	}
+	if err := CheckEvidence(A, B, nil); err != nil {
+		t.Errorf("expected no diagnostic for empty results, got %v", err)
+	}
+}
+
+func TestCompareSamplesIntMatchesFloat(t *testing.T) {
+	A := []int64{100, 101, 99, 98, 102, 103, 97, 104, 96, 105, 100}
+	B := []int64{120, 118, 122, 119, 121, 117, 123, 116, 124, 115, 99}
+	thresholds := []float64{0.0, 0.1, 0.2}
+	resultsInt, err := CompareSamplesInt(A, B, thresholds, 1000)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(resultsInt) != len(thresholds) {
+		t.Fatalf("expected %d results, got %d", len(thresholds), len(resultsInt))
+	}
+	if resultsInt[0].Confidence < 0.95 {
+		t.Errorf("expected high confidence at threshold 0, got %.3f", resultsInt[0].Confidence)
+	}
+	if resultsInt[2].Confidence > 0.05 {
+		t.Errorf("expected low confidence at threshold 0.2, got %.3f", resultsInt[2].Confidence)
+	}
+
+	// with a fixed seed the int and float paths draw the same resamples
+	fA := make([]float64, len(A))
+	fB := make([]float64, len(B))
+	for i := range A {
+		fA[i] = float64(A[i])
+		fB[i] = float64(B[i])
+	}
+	if !reflect.DeepEqual(bootstrapConfidence(A, B, thresholds, 500, 42), BootstrapConfidence(fA, fB, thresholds, 500, 42)) {
+		t.Errorf("int64 and float64 bootstrap differ for identical data")
+	}
+}
+
+func TestCompareSamplesIntLargeValues(t *testing.T) {
+	// values beyond 2^53 differ only by 1 and cannot be told apart as float64
+	base := int64(1) << 60
+	A := make([]int64, 11)
+	B := make([]int64, 11)
+	for i := range A {
+		A[i] = base
+		B[i] = base + 1
+	}
+	if float64(A[0]) != float64(B[0]) {
+		t.Fatalf("test precondition failed: values are distinguishable as float64")
+	}
+	if medianAsFloat(slices.Clone(A)) != float64(base) {
+		t.Errorf("expected exact int64 median")
+	}
+	if got := selectKth(slices.Clone(B), 5); got != base+1 {
+		t.Errorf("expected exact int64 median %d, got %d", base+1, got)
+	}
+	_, err := CompareSamplesInt(A[:10], B, nil, 10)
+	if err == nil {
+		t.Errorf("Expected error for too few data points, got nil")
+	}
+}
+
+// indexStreamCorrelation returns the largest absolute Pearson correlation, over the first `draws` positions,
+// between the bootstrap indices drawn for A and for B across `replicates` replicates derived by seeds.
+func indexStreamCorrelation(seeds func(i uint64) (uint64, uint64), replicates uint64, draws int, n uint32) float64 {
+	a := make([][]float64, draws)
+	b := make([][]float64, draws)
+	for i := range replicates {
+		seedA, seedB := seeds(i)
+		rngA, rngB := NewDPRNG(seedA), NewDPRNG(seedB)
+		for j := range draws {
+			a[j] = append(a[j], float64(rngA.UInt32N(n)))
+			b[j] = append(b[j], float64(rngB.UInt32N(n)))
+		}
+	}
+	worst := 0.0
+	for j := range draws {
+		meanA, _, sdA := Statistics(a[j])
+		meanB, _, sdB := Statistics(b[j])
+		cov := 0.0
+		for k := range a[j] {
+			cov += (a[j][k] - meanA) * (b[j][k] - meanB)
+		}
+		r := cov / float64(len(a[j])) / (sdA * sdB)
+		worst = math.Max(worst, math.Abs(r))
+	}
+	return worst
+}
+
+func TestReplicateSeedsNoCrossCorrelation(t *testing.T) {
+	const replicates = 4000
+	for _, base := range []uint64{1, 2, 42, 0x1234567890ABCDEF} {
+		r := indexStreamCorrelation(func(i uint64) (uint64, uint64) { return replicateSeeds(base, i) }, replicates, 8, 1000)
+		// the standard error of r is about 1/sqrt(4000) ≈ 0.016
+		if r > 0.08 {
+			t.Errorf("base seed %d: correlation %.4f between index streams of A and B", base, r)
+		}
+	}
+}
+
+func TestReplicateSeedsDistinctAndNonZero(t *testing.T) {
+	if a, b := replicateSeeds(0, 7); a != 0 || b != 0 {
+		t.Errorf("expected zero seeds for base seed 0, got %d, %d", a, b)
+	}
+	seen := make(map[uint64]bool)
+	for _, base := range []uint64{1, 2, 3} {
+		for i := range uint64(1000) {
+			a, b := replicateSeeds(base, i)
+			if a == 0 || b == 0 {
+				t.Fatalf("zero seed for base %d, replicate %d", base, i)
+			}
+			seen[a], seen[b] = true, true
+		}
+	}
+	// a linear derivation lets neighbouring base seeds share most of their replicate seeds
+	if len(seen) != 6000 {
+		t.Errorf("expected 6000 distinct seeds, got %d", len(seen))
+	}
+}
+
+func TestT2F(t *testing.T) {
+	for _, tf := range []float64{0.25, 0.5, 1, 1.5, 2, 3, 100} {
+		if got := T2F(F2T(tf)); math.Abs(got-tf) > 1e-9*tf {
+			t.Errorf("T2F(F2T(%v)) = %v", tf, got)
+		}
+	}
+	if got := T2F(1); !math.IsInf(got, 1) {
+		t.Errorf("T2F(1) should be +Inf, got %v", got)
+	}
+	for _, th := range []float64{1.5, math.NaN()} {
+		if got := T2F(th); !math.IsNaN(got) {
+			t.Errorf("T2F(%v) should be NaN, got %v", th, got)
+		}
+	}
+}
+
+func TestSpeedupFactorCI(t *testing.T) {
+	rng := NewDPRNG(42)
+	A := make([]float64, 200)
+	B := make([]float64, 200)
+	for i := range A {
+		A[i] = 100 + rng.Float64()*10
+		B[i] = 2 * (100 + rng.Float64()*10)
+	}
+	point, lo, hi := SpeedupFactorCI(A, B, 0.95, 2000, 12345)
+	if !(lo <= point && point <= hi) {
+		t.Errorf("point estimate %v not within CI [%v, %v]", point, lo, hi)
+	}
+	if math.Abs(point-2) > 0.1 || lo < 1.8 || hi > 2.2 {
+		t.Errorf("expected a speedup factor of about 2, got %v [%v, %v]", point, lo, hi)
+	}
+
+	// reproducible for a fixed seed, and a narrower confidence yields a narrower interval
+	p2, lo2, hi2 := SpeedupFactorCI(A, B, 0.95, 2000, 12345)
+	if p2 != point || lo2 != lo || hi2 != hi {
+		t.Errorf("results differ for the same seed")
+	}
+	_, lo3, hi3 := SpeedupFactorCI(A, B, 0.5, 2000, 12345)
+	if lo3 < lo || hi3 > hi {
+		t.Errorf("50%% CI [%v, %v] not within 95%% CI [%v, %v]", lo3, hi3, lo, hi)
+	}
+
+	// swapping the inputs inverts the factor
+	pInv, _, _ := SpeedupFactorCI(B, A, 0.95, 100, 1)
+	if math.Abs(pInv*point-1) > 1e-9 {
+		t.Errorf("expected inverse factor, got %v and %v", point, pInv)
+	}
+}
+
+func TestSpeedupFactorCIInvalidInput(t *testing.T) {
+	A := []float64{1, 2, 3}
+	testCases := []struct {
+		name       string
+		A, B       []float64
+		confidence float64
+		resamples  uint64
+	}{
+		{"empty A", nil, A, 0.95, 100},
+		{"empty B", A, nil, 0.95, 100},
+		{"zero resamples", A, A, 0.95, 0},
+		{"confidence 0", A, A, 0, 100},
+		{"confidence 1", A, A, 1, 100},
+		{"confidence NaN", A, A, math.NaN(), 100},
+	}
+	for _, tc := range testCases {
+		point, lo, hi := SpeedupFactorCI(tc.A, tc.B, tc.confidence, tc.resamples, 1)
+		if !math.IsNaN(point) || !math.IsNaN(lo) || !math.IsNaN(hi) {
+			t.Errorf("%s: expected NaN results, got %v [%v, %v]", tc.name, point, lo, hi)
+		}
+	}
+}
+
+func TestRequiredSamples(t *testing.T) {
+	baseline := []float64{90, 110, 90, 110} // mean 100, sample variance 400/3
+	z := NormalQuantile(0.95)
+	want := int(math.Ceil(2 * z * z * (400.0 / 3) / (5 * 5) * math.Pi / 2))
+	if got := RequiredSamples(baseline, 0.05, 0.95); got != want {
+		t.Errorf("RequiredSamples = %d; want %d", got, want)
+	}
+	if got := RequiredSamples(baseline, -0.05, 0.95); got != want {
+		t.Errorf("negative effect: RequiredSamples = %d; want %d", got, want)
+	}
+	// smaller effects and higher confidence need more samples
+	if RequiredSamples(baseline, 0.01, 0.95) <= want || RequiredSamples(baseline, 0.05, 0.99) <= want {
+		t.Errorf("expected more samples for smaller effect or higher confidence")
+	}
+	if got := RequiredSamples([]float64{100, 100, 100}, 0.05, 0.95); got != int(MinimumDataPoints) {
+		t.Errorf("constant baseline: RequiredSamples = %d; want %d", got, MinimumDataPoints)
+	}
+
+	// the estimate should roughly achieve the target confidence in a simulated comparison
+	rng := NewDPRNG(42)
+	pilot := make([]float64, 200)
+	for i := range pilot {
+		pilot[i] = 100 + 10*NormalQuantile(rng.Float64())
+	}
+	n := RequiredSamples(pilot, 0.05, 0.9)
+	var sum float64
+	const trials = 20
+	for trial := range trials {
+		A := make([]float64, n)
+		B := make([]float64, n)
+		for i := range n {
+			A[i] = 95 + 10*NormalQuantile(rng.Float64())
+			B[i] = 100 + 10*NormalQuantile(rng.Float64())
+		}
+		res, err := CompareSamplesWithOptions(A, B, []float64{0}, CompareOptions{Resamples: 1000, Seed: uint64(trial + 1)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum += res[0].Confidence
+	}
+	if avg := sum / trials; avg < 0.8 {
+		t.Errorf("n=%d: average confidence %v far below target 0.9", n, avg)
+	}
+
+	for _, tc := range []struct {
+		name       string
+		baseline   []float64
+		effect     float64
+		confidence float64
+	}{
+		{"too few values", []float64{100}, 0.05, 0.95},
+		{"zero mean", []float64{-1, 1}, 0.05, 0.95},
+		{"zero effect", baseline, 0, 0.95},
+		{"NaN effect", baseline, math.NaN(), 0.95},
+		{"confidence 0.5", baseline, 0.05, 0.5},
+		{"confidence 1", baseline, 0.05, 1},
+	} {
+		if got := RequiredSamples(tc.baseline, tc.effect, tc.confidence); got != 0 {
+			t.Errorf("%s: RequiredSamples = %d; want 0", tc.name, got)
+		}
+	}
+}
+
+func TestQuantileComparison(t *testing.T) {
+	// A is faster in the body of the distribution but has a heavy tail
+	A := []float64{50, 51, 52, 53, 54, 55, 56, 57, 58, 300}
+	B := []float64{100, 101, 102, 103, 104, 105, 106, 107, 108, 109}
+	origA := slices.Clone(A)
+	got := QuantileComparison(A, B, []float64{0, 0.5, 0.99, 1.5, math.NaN()})
+	if !slices.Equal(origA, A) {
+		t.Errorf("input must not be modified")
+	}
+	if want := 1 - 50.0/100; got[0] != want {
+		t.Errorf("q=0: expected %v, got %v", want, got[0])
+	}
+	if want := 1 - Percentile(A, 50)/Percentile(B, 50); got[0.5] != want || want <= 0 {
+		t.Errorf("q=0.5: expected positive %v, got %v", want, got[0.5])
+	}
+	if want := 1 - 300.0/109; got[0.99] != want || want >= 0 {
+		t.Errorf("q=0.99: expected negative %v, got %v", want, got[0.99])
+	}
+	if !math.IsNaN(got[1.5]) {
+		t.Errorf("q=1.5: expected NaN, got %v", got[1.5])
+	}
+	if len(got) != 5 {
+		t.Errorf("expected 5 entries, got %d", len(got))
+	}
+
+	for q, d := range QuantileComparison(nil, B, []float64{0.5, 0.9}) {
+		if !math.IsNaN(d) {
+			t.Errorf("empty A, q=%v: expected NaN, got %v", q, d)
+		}
+	}
+}
+
+func TestCompareToTarget(t *testing.T) {
+	rng := NewDPRNG(7)
+	samples := SyntheticSamples(101, 4.5, 0, 0.2, &rng)
+	orig := slices.Clone(samples)
+
+	if got := CompareToTarget(samples, 5, 2000, 42); got < 0.99 {
+		t.Errorf("median clearly below target: expected confidence near 1, got %v", got)
+	}
+	if !slices.Equal(orig, samples) {
+		t.Errorf("input must not be modified")
+	}
+	if got := CompareToTarget(samples, 4, 2000, 42); got > 0.01 {
+		t.Errorf("median clearly above target: expected confidence near 0, got %v", got)
+	}
+	if got := CompareToTarget(samples, Median(slices.Clone(samples)), 2000, 42); got < 0.2 || got > 0.8 {
+		t.Errorf("target at the median: expected confidence near 0.5, got %v", got)
+	}
+	if a, b := CompareToTarget(samples, 4.5, 500, 9), CompareToTarget(samples, 4.5, 500, 9); a != b {
+		t.Errorf("expected reproducible results for a fixed seed, got %v and %v", a, b)
+	}
+	if got := CompareToTarget([]float64{3, 3, 3}, 3, 100, 1); got != 0 {
+		t.Errorf("median equal to target is not below it: expected 0, got %v", got)
+	}
+
+	for _, tc := range []struct {
+		name      string
+		samples   []float64
+		target    float64
+		resamples uint64
+	}{
+		{"empty samples", nil, 5, 100},
+		{"zero resamples", samples, 5, 0},
+		{"NaN target", samples, math.NaN(), 100},
+	} {
+		if got := CompareToTarget(tc.samples, tc.target, tc.resamples, 1); !math.IsNaN(got) {
+			t.Errorf("%s: expected NaN, got %v", tc.name, got)
+		}
+	}
+}
+
+func TestCompareSamplesByFactor(t *testing.T) {
+	rng := NewDPRNG(11)
+	A := SyntheticSamples(200, 100, 0, 2, &rng)
+	B := SyntheticSamples(200, 300, 0, 2, &rng) // A is about 3× faster
+	factors := []float64{4, 2, 1.5}
+	orig := slices.Clone(factors)
+
+	results, err := CompareSamplesByFactor(A, B, factors, 500)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(orig, factors) {
+		t.Errorf("factors must not be modified")
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	for i, want := range []float64{1.5, 2, 4} {
+		r := results[i]
+		if r.FactorThreshold != want || r.RelativeSpeedupSampleAvsSampleB != F2T(want) {
+			t.Errorf("result %d: expected factor %v (threshold %v), got %v (%v)", i, want, F2T(want), r.FactorThreshold, r.RelativeSpeedupSampleAvsSampleB)
+		}
+	}
+	if results[0].Confidence != 1 || results[1].Confidence != 1 || results[2].Confidence != 0 {
+		t.Errorf("expected confidences 1, 1, 0 for a 3× speedup, got %v, %v, %v", results[0].Confidence, results[1].Confidence, results[2].Confidence)
+	}
+
+	results, err = CompareSamplesByFactor(A, B, nil, 100)
+	if err != nil || len(results) != 1 || results[0].FactorThreshold != 1 || results[0].RelativeSpeedupSampleAvsSampleB != 0 {
+		t.Errorf("nil factors: expected a single result for factor 1, got %v, %v", results, err)
+	}
+
+	for _, f := range []float64{0, -2, math.NaN()} {
+		if _, err := CompareSamplesByFactor(A, B, []float64{2, f}, 100); !errors.Is(err, ErrInvalidFactor) {
+			t.Errorf("factor %v: expected ErrInvalidFactor, got %v", f, err)
+		}
+	}
+	if _, err := CompareSamplesByFactor(A[:3], B, []float64{2}, 100); !errors.Is(err, ErrTooFewDataPoints) {
+		t.Errorf("expected ErrTooFewDataPoints, got %v", err)
+	}
+}
+
+func TestConfidenceFromDeltas(t *testing.T) {
+	deltas := []float64{0.3, math.NaN(), 0.1, 0.2, 0.1, math.NaN()}
+	orig := slices.Clone(deltas)
+	got := ConfidenceFromDeltas(deltas, []float64{0, 0.1, 0.15, 0.3, 0.31})
+	for threshold, want := range map[float64]float64{0: 1, 0.1: 1, 0.15: 0.5, 0.3: 0.25, 0.31: 0} {
+		if got[threshold] != want {
+			t.Errorf("threshold %v: expected %v, got %v", threshold, want, got[threshold])
+		}
+	}
+	if !slices.EqualFunc(orig, deltas, func(a, b float64) bool { return a == b || (math.IsNaN(a) && math.IsNaN(b)) }) {
+		t.Errorf("deltas must not be modified")
+	}
+	for _, d := range [][]float64{nil, {math.NaN()}} {
+		if got := ConfidenceFromDeltas(d, []float64{0}); !math.IsNaN(got[0]) {
+			t.Errorf("deltas %v: expected NaN, got %v", d, got[0])
+		}
+	}
+
+	// thresholding the deltas of one bootstrap run reproduces BootstrapConfidence
+	A := []float64{100, 101, 99, 98, 102, 103, 97, 104, 96, 105, 100}
+	B := []float64{110, 108, 112, 109, 111, 107, 113, 106, 114, 105, 110}
+	thresholds := []float64{0, 0.05, 0.1, 0.15}
+	want := BootstrapConfidence(A, B, thresholds, 1000, 77)
+	got = ConfidenceFromDeltas(BootstrapDeltas(A, B, 1000, 77), thresholds)
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestRecommendResamples(t *testing.T) {
+	for _, tc := range []struct {
+		p, se float64
+		want  uint64
+	}{
+		{0.5, 0.005, 10_000},                // 0.25 / 0.000025
+		{0.9, 0.005, 3_600},                 // 0.09 / 0.000025
+		{0.99, 0.001, 9_900},                // 0.0099 / 0.000001
+		{0.5, 0.1, MinRecommendedResamples}, // 25 is raised to the minimum
+		{0, 0.001, MinRecommendedResamples},
+		{1, 0.001, MinRecommendedResamples},
+		{0.5, 1e-6, MaxRecommendedResamples},
+	} {
+		if got := RecommendResamples(tc.p, tc.se); got != tc.want {
+			t.Errorf("RecommendResamples(%v, %v) = %d; want %d", tc.p, tc.se, got, tc.want)
+		}
+	}
+	for _, tc := range [][2]float64{{-0.1, 0.01}, {1.1, 0.01}, {math.NaN(), 0.01}, {0.5, 0}, {0.5, -0.01}, {0.5, math.NaN()}} {
+		if got := RecommendResamples(tc[0], tc[1]); got != 0 {
+			t.Errorf("RecommendResamples(%v, %v) = %d; want 0", tc[0], tc[1], got)
+		}
+	}
+}
+
+func TestConfidenceLowerBound(t *testing.T) {
+	A := []float64{90, 91, 89, 88, 92, 93, 87, 94, 86, 95, 90}
+	B := []float64{100, 101, 99, 98, 102, 103, 97, 104, 96, 105, 100}
+	results, err := CompareSamplesWithOptions(A, B, []float64{0, 0.1, 0.5}, CompareOptions{Resamples: 2000, Seed: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, r := range results {
+		if want := wilsonLowerBound(r.Confidence, 2000); r.ConfidenceLowerBound != want {
+			t.Errorf("threshold %v: expected lower bound %v, got %v", r.RelativeSpeedupSampleAvsSampleB, want, r.ConfidenceLowerBound)
+		}
+		if r.ConfidenceLowerBound > r.Confidence || r.ConfidenceLowerBound < 0 {
+			t.Errorf("threshold %v: lower bound %v not in [0, %v]", r.RelativeSpeedupSampleAvsSampleB, r.ConfidenceLowerBound, r.Confidence)
+		}
+	}
+	if results[0].Confidence != 1 || !(results[0].ConfidenceLowerBound > 0.99 && results[0].ConfidenceLowerBound < 1) {
+		t.Errorf("expected a lower bound slightly below 1 for confidence 1, got %v", results[0].ConfidenceLowerBound)
+	}
+
+	results, _ = CompareSamples(A, B, nil, 0)
+	if !math.IsNaN(results[0].ConfidenceLowerBound) {
+		t.Errorf("expected NaN lower bound for zero resamples, got %v", results[0].ConfidenceLowerBound)
+	}
+}
+
+func TestConfidenceGrid(t *testing.T) {
+	rng := NewDPRNG(17)
+	A := SyntheticSamples(200, 100, -0.05, 10, &rng)
+	B := SyntheticSamples(150, 100, 0, 10, &rng)
+	gains := []float64{0, 0.1}
+	grid := ConfidenceGrid(A, B, gains, []int{5, 11, 50, 150, 151}, 1000, 3)
+	if len(grid) != 3 {
+		t.Fatalf("expected entries for the sizes 11, 50 and 150 only, got %v", grid)
+	}
+	for _, size := range []int{11, 50, 150} {
+		want := BootstrapConfidence(A[:size], B[:size], gains, 1000, 3)
+		for _, g := range gains {
+			if grid[size][g] != want[g] {
+				t.Errorf("size %d, threshold %v: got %v, want %v", size, g, grid[size][g], want[g])
+			}
+		}
+	}
+	if grid[150][0] <= grid[11][0] {
+		t.Errorf("confidence for threshold 0 should grow with the sample size: %v at 11, %v at 150", grid[11][0], grid[150][0])
+	}
+	if _, ok := ConfidenceGrid(A, B, nil, []int{20}, 100, 3)[20][0]; !ok {
+		t.Errorf("nil thresholds should select the threshold 0")
+	}
+}
+
+// BenchmarkBootstrapDeltasSmall measures the bootstrap for the common small-sample case, where the
+// per-replicate overhead (allocating the samples, seeding the PRNGs) is significant compared to the medians.
+func BenchmarkBootstrapDeltasSmall(b *testing.B) {
+	for _, n := range []int{11, 31, 101} {
+		rng := NewDPRNG(1)
+		A := SyntheticSamples(n, 100, 0, 5, &rng)
+		B := SyntheticSamples(n, 100, 0, 5, &rng)
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			for b.Loop() {
+				bootstrapDeltas(A, B, 1000, 7, relativeDelta)
+			}
+		})
+	}
+}
+
+func TestProbabilitySuperiority(t *testing.T) {
+	bruteForce := func(A, B []float64) float64 {
+		var wins float64
+		for _, a := range A {
+			for _, b := range B {
+				if a < b {
+					wins++
+				} else if a == b {
+					wins += 0.5
+				}
+			}
+		}
+		return wins / float64(len(A)*len(B))
+	}
+	rng := NewDPRNG(23)
+	for _, distinct := range []uint32{2, 5, 1000} {
+		for range 50 {
+			A := make([]float64, 1+rng.UInt32N(30))
+			B := make([]float64, 1+rng.UInt32N(30))
+			for i := range A {
+				A[i] = float64(rng.UInt32N(distinct))
+			}
+			for i := range B {
+				B[i] = float64(rng.UInt32N(distinct))
+			}
+			origA := slices.Clone(A)
+			if got, want := ProbabilitySuperiority(A, B), bruteForce(A, B); math.Abs(got-want) > 1e-12 {
+				t.Errorf("A=%v B=%v: got %v, want %v", A, B, got, want)
+			}
+			if !slices.Equal(A, origA) {
+				t.Fatalf("input was modified")
+			}
+		}
+	}
+
+	if got := ProbabilitySuperiority([]float64{1, 2}, []float64{3, 4}); got != 1 {
+		t.Errorf("A entirely below B: got %v, want 1", got)
+	}
+	if got := ProbabilitySuperiority([]float64{3, 4}, []float64{1, 2}); got != 0 {
+		t.Errorf("A entirely above B: got %v, want 0", got)
+	}
+	if got := ProbabilitySuperiority([]float64{5, 5}, []float64{5}); got != 0.5 {
+		t.Errorf("all ties: got %v, want 0.5", got)
+	}
+	for _, in := range [][2][]float64{{nil, {1}}, {{1}, {}}, {{1, math.NaN()}, {2}}} {
+		if got := ProbabilitySuperiority(in[0], in[1]); !math.IsNaN(got) {
+			t.Errorf("ProbabilitySuperiority(%v, %v) = %v, want NaN", in[0], in[1], got)
+		}
+	}
+}
+
+func TestStochasticDominance(t *testing.T) {
+	B := []float64{10, 12, 14, 16, 18, 20}
+	A := []float64{9, 11, 13, 15, 17, 19}
+	aDom, bDom, cross := StochasticDominance(A, B)
+	if !aDom || bDom || cross != nil {
+		t.Errorf("shifted A: got %v, %v, %v; want true, false, nil", aDom, bDom, cross)
+	}
+	aDom, bDom, cross = StochasticDominance(B, A)
+	if aDom || !bDom || cross != nil {
+		t.Errorf("swapped: got %v, %v, %v; want false, true, nil", aDom, bDom, cross)
+	}
+
+	// the ECDFs touch but do not cross: still dominance
+	aDom, bDom, cross = StochasticDominance([]float64{1, 2, 5}, []float64{2, 3, 5})
+	if !aDom || bDom || cross != nil {
+		t.Errorf("touching ECDFs: got %v, %v, %v; want true, false, nil", aDom, bDom, cross)
+	}
+
+	// A is narrower than B: faster in the upper half, slower in the lower half
+	narrow := []float64{14, 15, 15, 16}
+	wide := []float64{10, 12, 18, 20}
+	aDom, bDom, cross = StochasticDominance(narrow, wide)
+	if aDom || bDom || !slices.Equal(cross, []float64{15}) {
+		t.Errorf("crossing: got %v, %v, %v; want false, false, [15]", aDom, bDom, cross)
+	}
+
+	same := []float64{3, 1, 2}
+	orig := slices.Clone(same)
+	aDom, bDom, cross = StochasticDominance(same, slices.Clone(same))
+	if aDom || bDom || cross != nil {
+		t.Errorf("identical: got %v, %v, %v; want false, false, nil", aDom, bDom, cross)
+	}
+	if !slices.Equal(same, orig) {
+		t.Fatalf("input was modified")
+	}
+
+	for _, tc := range [][2][]float64{{nil, B}, {A, nil}, {{1, math.NaN()}, B}} {
+		aDom, bDom, cross = StochasticDominance(tc[0], tc[1])
+		if aDom || bDom || cross != nil {
+			t.Errorf("A=%v B=%v: got %v, %v, %v; want false, false, nil", tc[0], tc[1], aDom, bDom, cross)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package rtcompare
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// ValidateSamples checks that xs is not empty and contains only finite values. It returns an error
+// wrapping ErrEmptySample for an empty slice, an error wrapping ErrNonFiniteValues that states how many
+// NaN and how many ±Inf values were found, or nil if all values are finite.
+//
+// CompareSamples and BootstrapConfidence do not reject non-finite values: a NaN or ±Inf median in a
+// bootstrap replicate never meets any threshold, so the reported confidences silently drop towards 0.
+// Call ValidateSamples on both inputs first to reject such data explicitly, or use CleanSamples to
+// strip the offending values.
+func ValidateSamples(xs []float64) error {
+	if len(xs) == 0 {
+		return ErrEmptySample
+	}
+	nans, infs := 0, 0
+	for _, x := range xs {
+		if math.IsNaN(x) {
+			nans++
+		} else if math.IsInf(x, 0) {
+			infs++
+		}
+	}
+	if nans+infs > 0 {
+		return fmt.Errorf("%w: %d of %d samples (%d NaN, %d ±Inf)", ErrNonFiniteValues, nans+infs, len(xs), nans, infs)
+	}
+	return nil
+}
+
+// CleanSamples returns a new slice containing only the finite values of xs, in their original order.
+// NaN and ±Inf values are dropped. The input slice is not modified.
+// Note that the result may contain fewer than MinimumDataPoints values or even be empty; ValidateSamples
+// reports the latter as ErrEmptySample and CompareSamples the former as ErrTooFewDataPoints.
+func CleanSamples(xs []float64) []float64 {
+	result := make([]float64, 0, len(xs))
+	for _, x := range xs {
+		if !math.IsNaN(x) && !math.IsInf(x, 0) {
+			result = append(result, x)
+		}
+	}
+	return result
+}
+
+// FilterReport describes what a filter such as RemoveOutliersReport removed from a sample, so that data
+// cleaning stays auditable, e.g. in CI logs, and the surviving sample size can be checked against
+// MinimumDataPoints before calling CompareSamples.
+type FilterReport struct {
+	// Original is the number of values before filtering.
+	Original int
+	// Removed is the number of values the filter dropped.
+	Removed int
+	// Lower and Upper are the thresholds used: values below Lower or above Upper were removed.
+	Lower, Upper float64
+}
+
+// Remaining returns the number of values that survived the filter.
+func (r FilterReport) Remaining() int {
+	return r.Original - r.Removed
+}
+
+// CheckMinimum returns an error wrapping ErrTooFewDataPoints if fewer than minimum values survived the
+// filter (pass MinimumDataPoints for the requirement of CompareSamples), and nil otherwise.
+func (r FilterReport) CheckMinimum(minimum uint64) error {
+	if uint64(r.Remaining()) < minimum {
+		return fmt.Errorf("%w: %d of %d values remain after filtering, need at least %d", ErrTooFewDataPoints, r.Remaining(), r.Original, minimum)
+	}
+	return nil
+}
+
+// String returns a one-line summary of the report suitable for logging.
+func (r FilterReport) String() string {
+	return fmt.Sprintf("removed %d of %d values outside [%g, %g], %d remaining", r.Removed, r.Original, r.Lower, r.Upper, r.Remaining())
+}
+
+// RemoveOutliers returns a new slice containing the values of xs within Tukey's fences
+// [Q1 - k·IQR, Q3 + k·IQR], in their original order, where Q1 and Q3 are the 25th and 75th percentiles
+// (see Percentile) and IQR = Q3 - Q1. k = 1.5 is the common choice; larger values remove fewer values.
+// Runtime measurements are typically right-skewed by interruptions (scheduling, GC, interrupts), which
+// produce isolated large values; removing them makes the comparison less sensitive to such noise.
+// NaN values are always removed. The input slice is not modified. Use RemoveOutliersReport to learn
+// how many values were removed.
+func RemoveOutliers(xs []float64, k float64) []float64 {
+	result, _ := RemoveOutliersReport(xs, k)
+	return result
+}
+
+// RemoveOutliersReport is like RemoveOutliers but additionally returns a FilterReport with the number of
+// removed values and the fences used. For an empty input, or a negative or NaN k, nothing is removed
+// except NaN values, and the fences are -Inf and +Inf.
+func RemoveOutliersReport(xs []float64, k float64) ([]float64, FilterReport) {
+	lower, upper := math.Inf(-1), math.Inf(1)
+	sorted := slices.DeleteFunc(slices.Clone(xs), math.IsNaN)
+	if len(sorted) > 0 && k >= 0 {
+		slices.Sort(sorted)
+		q1 := PercentileSorted(sorted, 25)
+		q3 := PercentileSorted(sorted, 75)
+		iqr := q3 - q1
+		lower, upper = q1-k*iqr, q3+k*iqr
+	}
+	result := make([]float64, 0, len(xs))
+	for _, x := range xs {
+		if x >= lower && x <= upper {
+			result = append(result, x)
+		}
+	}
+	return result, FilterReport{Original: len(xs), Removed: len(xs) - len(result), Lower: lower, Upper: upper}
+}
+
+// ScaleSamples returns a new slice with every value of xs multiplied by factor, e.g. ScaleSamples(xs, 1000)
+// to convert microseconds to nanoseconds before comparing them with measurements taken in nanoseconds.
+// The input slice is not modified. Returns nil for a nil input.
+func ScaleSamples(xs []float64, factor float64) []float64 {
+	if xs == nil {
+		return nil
+	}
+	result := make([]float64, len(xs))
+	for i, x := range xs {
+		result[i] = x * factor
+	}
+	return result
+}
+
+// BatchMeans returns the mean of each consecutive block of batchSize values of xs, e.g. to turn a long
+// stream of per-iteration timings into a sample of per-batch means for CompareSamples. This is the batch
+// means technique: averaging within a batch reduces the noise of each value by about 1/sqrt(batchSize) and
+// weakens the correlation between neighboring iterations, so the batches behave more like the independent
+// measurements the bootstrap assumes. Choose batchSize so that enough batches remain (see MinimumDataPoints).
+//
+// A short final block with fewer than batchSize values is dropped rather than padded: its mean would be
+// noisier than the others, and padding with made-up values would bias it. The result therefore has
+// len(xs)/batchSize elements (rounded down), in the order of the blocks.
+// Returns nil if batchSize is not positive. The input is not modified.
+func BatchMeans(xs []float64, batchSize int) []float64 {
+	if batchSize <= 0 {
+		return nil
+	}
+	result := make([]float64, len(xs)/batchSize)
+	for i := range result {
+		sum := 0.0
+		for _, x := range xs[i*batchSize : (i+1)*batchSize] {
+			sum += x
+		}
+		result[i] = sum / float64(batchSize)
+	}
+	return result
+}
+
+// Thresholds used by AssessStability. A sample is considered stable if its coefficient of variation does
+// not exceed StableMaxCV and at most StableMaxOutlierFraction of its values lie outside Tukey's fences
+// (k = 1.5, see RemoveOutliers).
+const (
+	StableMaxCV              = 0.10
+	StableMaxOutlierFraction = 0.05
+)
+
+// AssessStability is a quick check whether a sample is clean enough for CompareSamples to be conclusive. It
+// returns the coefficient of variation cv = stddev/mean (see Statistics), the fraction of values outside
+// Tukey's fences with k = 1.5, and whether both are within StableMaxCV and StableMaxOutlierFraction.
+// An unstable sample does not make a comparison wrong, but it makes low confidences likely to mean "too
+// noisy" rather than "no difference"; collect more or cleaner data (e.g. with WithGCOff or Interleave)
+// before interpreting such results.
+//
+// The thresholds are rules of thumb for runtime measurements: repeated timings of the same code on a quiet
+// machine usually vary by a few percent, while a CV above 10% or more than 5% outliers typically indicate
+// interference such as frequency scaling, other processes or garbage collection.
+//
+// Returns math.NaN() for cv and outlierFraction and stable = false if samples has fewer than two values or
+// contains non-finite values. cv is math.NaN() (and stable false) if the mean is not positive, since the
+// coefficient of variation is only meaningful for positive quantities. The input is not modified.
+func AssessStability(samples []float64) (cv float64, outlierFraction float64, stable bool) {
+	if len(samples) < 2 || ValidateSamples(samples) != nil {
+		return math.NaN(), math.NaN(), false
+	}
+	mean, _, stddev := Statistics(samples)
+	cv = math.NaN()
+	if mean > 0 {
+		cv = stddev / mean
+	}
+	_, report := RemoveOutliersReport(samples, 1.5)
+	outlierFraction = float64(report.Removed) / float64(report.Original)
+	return cv, outlierFraction, cv <= StableMaxCV && outlierFraction <= StableMaxOutlierFraction
+}
+
+// AlignAndMerge combines several independent runs of the same measurement (e.g. taken on different days)
+// into one sample. Simply concatenating such runs inflates the spread if the runs are offset against each
+// other, e.g. because of a different CPU frequency or system load. AlignAndMerge therefore shifts each run by
+// the difference between the grand median (the median of all values of all runs) and the run's own median
+// before concatenating the runs in their original order:
+//
+//	aligned = x - Median(run) + Median(all runs)
+//
+// The within-run spread is preserved. This assumes that the runs differ only by an additive offset and
+// otherwise measure the same distribution; a multiplicative change (e.g. everything taking 10% longer) is
+// only approximately removed. Align each implementation separately before calling CompareSamples, and keep
+// in mind that any genuine difference between the runs is removed as well.
+//
+// Empty runs are skipped. The inputs are not modified. Returns an empty slice if there are no values.
+func AlignAndMerge(runs ...[]float64) []float64 {
+	total := 0
+	for _, run := range runs {
+		total += len(run)
+	}
+	result := make([]float64, 0, total)
+	for _, run := range runs {
+		result = append(result, run...)
+	}
+	if total == 0 {
+		return result
+	}
+	grandMedian := Median(result)
+	i := 0
+	for _, run := range runs {
+		if len(run) == 0 {
+			continue
+		}
+		shift := grandMedian - Median(run)
+		for range run {
+			result[i] += shift
+			i++
+		}
+	}
+	return result
+}
+
+// SyntheticSamples returns n synthetic measurements baseline·(1+relShift) + N(0, noiseStdDev), i.e. values
+// around a (possibly shifted) baseline with normally distributed noise. It allows constructing controlled
+// scenarios for tests and experiments, e.g. A := SyntheticSamples(n, 100, 0, 15, &rng) and
+// B := SyntheticSamples(n, 100, 0.05, 15, &rng) for an implementation B that is 5% slower than A.
+//
+// The noise is generated deterministically from rng by inverse transform sampling
+// (NormalQuantile(rng.Float64OpenOpen())), so the same seed always yields the same samples, unlike
+// math/rand.NormFloat64 with its global, randomly seeded source. Each value consumes one value of rng.
+// Returns nil if n is zero or negative.
+func SyntheticSamples(n int, baseline, relShift, noiseStdDev float64, rng *DPRNG) []float64 {
+	if n <= 0 {
+		return nil
+	}
+	result := make([]float64, n)
+	center := baseline * (1 + relShift)
+	for i := range result {
+		result[i] = center + noiseStdDev*NormalQuantile(rng.Float64OpenOpen())
+	}
+	return result
+}
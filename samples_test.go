@@ -0,0 +1,151 @@
+package rtcompare
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSamples(t *testing.T) {
+	assert.ErrorIs(t, ValidateSamples(nil), ErrEmptySample)
+	assert.NoError(t, ValidateSamples([]float64{1, 2, 3, -4, 0}))
+
+	err := ValidateSamples([]float64{1, math.NaN(), math.Inf(1), 2, math.Inf(-1), math.NaN()})
+	if assert.ErrorIs(t, err, ErrNonFiniteValues) {
+		assert.Contains(t, err.Error(), "4 of 6 samples")
+		assert.Contains(t, err.Error(), "2 NaN")
+		assert.Contains(t, err.Error(), "2 ±Inf")
+	}
+}
+
+func TestCleanSamples(t *testing.T) {
+	input := []float64{1, math.NaN(), 2, math.Inf(1), 3, math.Inf(-1)}
+	got := CleanSamples(input)
+	assert.Equal(t, []float64{1, 2, 3}, got)
+	assert.Len(t, input, 6, "input must not be modified")
+	assert.True(t, math.IsNaN(input[1]), "input must not be modified")
+	assert.Empty(t, CleanSamples([]float64{math.NaN()}))
+	assert.ErrorIs(t, ValidateSamples(CleanSamples([]float64{math.NaN(), math.Inf(1)})), ErrEmptySample)
+	assert.NoError(t, ValidateSamples(got))
+}
+
+func TestAlignAndMerge(t *testing.T) {
+	day1 := []float64{10, 11, 12}
+	day2 := []float64{20, 22, 24}
+	day3 := []float64{14, 15, 16, 17}
+	merged := AlignAndMerge(day1, nil, day2, day3)
+	// grand median of all ten values is 16
+	assert.Equal(t, []float64{15, 16, 17, 14, 16, 18, 14, 15, 16, 17}, merged)
+	assert.Equal(t, []float64{10, 11, 12}, day1, "input must not be modified")
+	assert.Equal(t, []float64{20, 22, 24}, day2, "input must not be modified")
+
+	// a single run is returned unchanged
+	assert.Equal(t, day2, AlignAndMerge(day2))
+	assert.Empty(t, AlignAndMerge())
+	assert.Empty(t, AlignAndMerge(nil, []float64{}))
+}
+
+func TestRemoveOutliersReport(t *testing.T) {
+	xs := []float64{10, 11, 12, 11, 10, 12, 11, 95, 10, 11, 1, 12}
+	got, report := RemoveOutliersReport(xs, 1.5)
+	// Q1 = 10, Q3 = 12 (index floor(p/100·n) of the sorted values, see Percentile), so the fences are [7, 15]
+	assert.Equal(t, []float64{10, 11, 12, 11, 10, 12, 11, 10, 11, 12}, got)
+	assert.Equal(t, FilterReport{Original: 12, Removed: 2, Lower: 7, Upper: 15}, report)
+	assert.Equal(t, 10, report.Remaining())
+	assert.Equal(t, "removed 2 of 12 values outside [7, 15], 10 remaining", report.String())
+	assert.Equal(t, got, RemoveOutliers(xs, 1.5))
+	assert.Equal(t, 95.0, xs[7], "input must not be modified")
+
+	assert.NoError(t, report.CheckMinimum(10))
+	err := report.CheckMinimum(MinimumDataPoints)
+	assert.ErrorIs(t, err, ErrTooFewDataPoints)
+	assert.Contains(t, err.Error(), "10 of 12 values remain")
+
+	got, report = RemoveOutliersReport([]float64{1, math.NaN(), 2}, -1)
+	assert.Equal(t, []float64{1, 2}, got)
+	assert.Equal(t, 1, report.Removed)
+	assert.True(t, math.IsInf(report.Lower, -1) && math.IsInf(report.Upper, 1))
+
+	got, report = RemoveOutliersReport(nil, 1.5)
+	assert.Empty(t, got)
+	assert.Equal(t, 0, report.Remaining())
+}
+
+func TestSyntheticSamples(t *testing.T) {
+	rng := NewDPRNG(42)
+	xs := SyntheticSamples(100_000, 100, 0.05, 15, &rng)
+	assert.Len(t, xs, 100_000)
+	mean, _, stddev := Statistics(xs)
+	assert.InDelta(t, 105, mean, 0.2)
+	assert.InDelta(t, 15, stddev, 0.2)
+
+	rng1, rng2 := NewDPRNG(7), NewDPRNG(7)
+	assert.Equal(t, SyntheticSamples(50, 100, 0, 1, &rng1), SyntheticSamples(50, 100, 0, 1, &rng2), "must be reproducible")
+	assert.Equal(t, []float64{150, 150}, SyntheticSamples(2, 100, 0.5, 0, &rng1), "no noise for noiseStdDev 0")
+	assert.Nil(t, SyntheticSamples(0, 100, 0, 1, &rng1))
+}
+
+func TestAssessStability(t *testing.T) {
+	rng := NewDPRNG(3)
+	quiet := SyntheticSamples(200, 100, 0, 2, &rng)
+	orig := slices.Clone(quiet)
+	cv, outliers, stable := AssessStability(quiet)
+	assert.Equal(t, orig, quiet, "input must not be modified")
+	assert.InDelta(t, 0.02, cv, 0.005)
+	assert.LessOrEqual(t, outliers, StableMaxOutlierFraction)
+	assert.True(t, stable)
+
+	noisy := SyntheticSamples(200, 100, 0, 20, &rng)
+	cv, _, stable = AssessStability(noisy)
+	assert.Greater(t, cv, StableMaxCV)
+	assert.False(t, stable)
+
+	// low spread, but too many outliers, e.g. from occasional interrupts
+	spiky := slices.Clone(quiet)
+	for i := 0; i < len(spiky); i += 10 {
+		spiky[i] = 110
+	}
+	cv, outliers, stable = AssessStability(spiky)
+	assert.LessOrEqual(t, cv, StableMaxCV)
+	assert.InDelta(t, 0.1, outliers, 0.02)
+	assert.False(t, stable)
+
+	cv, outliers, stable = AssessStability([]float64{-1, -2, -3})
+	assert.True(t, math.IsNaN(cv))
+	assert.Equal(t, 0.0, outliers)
+	assert.False(t, stable)
+
+	for _, xs := range [][]float64{nil, {1}, {1, 2, math.NaN()}, {1, math.Inf(1)}} {
+		cv, outliers, stable = AssessStability(xs)
+		assert.True(t, math.IsNaN(cv) && math.IsNaN(outliers) && !stable, "%v", xs)
+	}
+}
+
+func TestScaleSamples(t *testing.T) {
+	xs := []float64{1.5, 2, -3}
+	scaled := ScaleSamples(xs, 1000)
+	assert.Equal(t, []float64{1500, 2000, -3000}, scaled)
+	assert.Equal(t, []float64{1.5, 2, -3}, xs, "input must not be modified")
+	assert.Nil(t, ScaleSamples(nil, 2))
+	assert.Equal(t, []float64{}, ScaleSamples([]float64{}, 2))
+}
+
+func TestBatchMeans(t *testing.T) {
+	xs := []float64{1, 2, 3, 4, 5, 6, 7}
+	assert.Equal(t, []float64{2, 5}, BatchMeans(xs, 3), "the short final block must be dropped")
+	assert.Equal(t, xs, BatchMeans(xs, 1))
+	assert.Equal(t, []float64{4}, BatchMeans(xs, 7))
+	assert.Equal(t, []float64{}, BatchMeans(xs, 8))
+	assert.Equal(t, []float64{1, 2, 3, 4, 5, 6, 7}, xs, "input must not be modified")
+	assert.Nil(t, BatchMeans(xs, 0))
+	assert.Nil(t, BatchMeans(xs, -2))
+
+	// averaging reduces the spread by about 1/sqrt(batchSize)
+	rng := NewDPRNG(11)
+	raw := SyntheticSamples(10_000, 100, 0, 10, &rng)
+	_, _, rawStdDev := Statistics(raw)
+	_, _, batchStdDev := Statistics(BatchMeans(raw, 25))
+	assert.InDelta(t, rawStdDev/5, batchStdDev, rawStdDev/5*0.2)
+}
@@ -2,24 +2,89 @@ package rtcompare
 
 import (
 	"math"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const iterationsForCallibration = 10_000_000
 
 var (
 	// precision holds the precision of time measurements obtained via SampleTime() on the runtime system in nanoseconds.
-	precision     int64 = -1
+	// It is accessed atomically, so concurrent callers of GetSampleTimePrecision and RecalibrateSampleTimePrecision do not race.
+	precision     atomic.Int64
 	precisionOnce sync.Once
 )
 
+func init() {
+	precision.Store(-1)
+}
+
 // Returns the precision of time measurements obtained via SampleTime() on the runtime system in nanoseconds.
 // Should return 100ns on Windows systems, and typically between 20ns and 100ns on Linux and MacOS systems.
+// The precision is calibrated once on the first call (which takes about a second); subsequent calls return
+// the cached value. It is safe to call this function from multiple goroutines; concurrent first callers
+// block until the calibration has finished.
 func GetSampleTimePrecision() int64 {
 	precisionOnce.Do(func() {
-		precision = calcMinTimeSample()
+		precision.Store(calcMinTimeSample())
 	})
-	return precision
+	return precision.Load()
+}
+
+// RecalibrateSampleTimePrecision measures the precision of SampleTime() again, e.g. after the system's
+// power or frequency scaling settings have changed, stores it as the new value returned by
+// GetSampleTimePrecision, and returns it. It is safe to call this function concurrently with
+// GetSampleTimePrecision; readers observe either the old or the new value.
+func RecalibrateSampleTimePrecision() int64 {
+	p := calcMinTimeSample()
+	precisionOnce.Do(func() {}) // a pending first calibration must not overwrite the new value later
+	precision.Store(p)
+	return p
+}
+
+// MinMeaningfulDurationMultiple is the factor applied to GetSampleTimePrecision by MinMeaningfulDuration.
+const MinMeaningfulDurationMultiple = 100
+
+// MinMeaningfulDuration returns the shortest window between two SampleTime() calls that yields a meaningful
+// timing sample: MinMeaningfulDurationMultiple × GetSampleTimePrecision(), e.g. 10 µs with the 100 ns timer
+// of Windows. A timestamp is only accurate to about one timer tick, so the duration of a shorter window is
+// dominated by quantization noise: with a window of a few ticks, a measured duration can only take a few
+// distinct values, and the error of a single sample can reach tens of percent. With 100 ticks, the
+// quantization error of a sample stays below about 1%. Time enough repetitions of short operations
+// between two timestamps to reach this duration (see CalibrateInnerLoops and PerOpNanos); Benchmark
+// refuses to record shorter windows. DefaultPrecisionMultiple is stricter and aims at 0.1%.
+func MinMeaningfulDuration() time.Duration {
+	return time.Duration(MinMeaningfulDurationMultiple * GetSampleTimePrecision())
+}
+
+// MeasureTimerOverhead takes `iterations` pairs of back-to-back SampleTime() calls and returns the minimum,
+// (upper) median and maximum of the differences DiffTimeStamps reports for them, in nanoseconds. Use it to
+// check the clock of an unfamiliar system before trusting measurements taken on it:
+//   - median is the typical cost of a SampleTime() call plus the clock's quantization; it is the noise floor
+//     of a single measurement. Measure enough repetitions between two timestamps to stay well above it
+//     (see PerOpNanos).
+//   - min = 0 means that consecutive timestamps were often equal, i.e. the clock resolution is coarser than
+//     the call overhead. If even median is 0, the clock is too coarse to time short operations at all.
+//   - a negative min reveals a non-monotonic clock; max shows the outliers caused by interrupts or
+//     preemption and is expected to be much larger than median.
+//
+// GetSampleTimePrecision reports the smallest positive difference of a similar loop. MeasureTimerOverhead
+// allocates one int64 per iteration. Returns zeros if iterations is zero or negative.
+func MeasureTimerOverhead(iterations int) (min, median, max int64) {
+	if iterations <= 0 {
+		return 0, 0, 0
+	}
+	diffs := make([]int64, iterations)
+	for i := range diffs {
+		t1 := SampleTime()
+		t2 := SampleTime()
+		diffs[i] = DiffTimeStamps(t1, t2)
+	}
+	min, max = slices.Min(diffs), slices.Max(diffs)
+	median = selectKth(diffs, uint64(iterations/2))
+	return min, median, max
 }
 
 func calcMinTimeSample() int64 {
@@ -34,3 +99,38 @@ func calcMinTimeSample() int64 {
 	}
 	return minDiff
 }
+
+// PerOpNanos returns the average duration of a single operation in nanoseconds, given the
+// timestamps taken before and after ops repetitions of that operation.
+// Measuring many repetitions between two SampleTime() calls and dividing by their number is the
+// recommended way to reduce the quantization noise caused by the limited timer precision
+// (see GetSampleTimePrecision). The difference is taken with DiffTimeStampsFloat, so fractions of a
+// nanosecond are retained where the timer provides them. Returns math.NaN() if ops is zero or negative.
+func PerOpNanos(start, end TimeStamp, ops int) float64 {
+	if ops <= 0 {
+		return math.NaN()
+	}
+	return DiffTimeStampsFloat(start, end) / float64(ops)
+}
+
+// DiffNanos returns the difference between two timestamps obtained via SampleTimeNanos() in nanoseconds,
+// i.e. t_later - t_earlier. Like DiffTimeStamps, it returns a negative value if t_later is earlier than
+// t_earlier. Unlike DiffTimeStamps, it has a constant runtime on all platforms.
+func DiffNanos(t_earlier, t_later int64) int64 {
+	return t_later - t_earlier
+}
+
+// AbsDiffTimeStamps returns the absolute difference between two timestamps in nanoseconds,
+// regardless of their order.
+//
+// Use DiffTimeStamps when the order of the timestamps is known (t_earlier taken before t_later) and a
+// negative result should be noticed as an error. Use AbsDiffTimeStamps in measurement loops that must
+// never produce negative durations, e.g. to be robust against misordered arguments or occasional
+// non-monotonic clock readings on some platforms, which would otherwise corrupt per-operation averages.
+func AbsDiffTimeStamps(a, b TimeStamp) int64 {
+	d := DiffTimeStamps(a, b)
+	if d < 0 {
+		return -d
+	}
+	return d
+}
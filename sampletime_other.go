@@ -2,7 +2,10 @@
 
 package rtcompare
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 //import "golang.org/x/sys/unix"
 
@@ -25,8 +28,42 @@ func SampleTime() TimeStamp {
 
 // Retruns the difference between two timestams in nanoseconds with the highest possible precision (which might be more than just one nanosecond).
 // The function assumes that t_later is later than t_earlier and will return a negative value if this is not the case.
+// With a clock that is not monotonic, this can also happen for timestamps taken in order (see SampleTimeIsMonotonic).
 // Please note that the call to this function does NOT have constant runtime on other systems but Windows.
 func DiffTimeStamps(t_earlier, t_later TimeStamp) int64 {
 	result := t_later.Sub(t_earlier)
 	return result.Nanoseconds()
 }
+
+// DiffTimeStampsFloat returns the difference between two timestamps in nanoseconds like DiffTimeStamps, but as
+// a float64. On Windows it retains fractions of a nanosecond; on other systems the timestamps have a resolution
+// of one nanosecond, so the result is DiffTimeStamps converted to float64.
+func DiffTimeStampsFloat(t_earlier, t_later TimeStamp) float64 {
+	return float64(DiffTimeStamps(t_earlier, t_later))
+}
+
+// nanosBase is the reference point of SampleTimeNanos. time.Since uses the monotonic clock reading of it.
+var nanosBase = time.Now()
+
+// SampleTimeNanos returns a monotonic timestamp in nanoseconds as a plain int64, an alternative to SampleTime
+// for the tightest loops that avoids copying time.Time structs. Only differences between two values are
+// meaningful (see DiffNanos); they are comparable within the same runtime of a program only.
+// On this platform the value is the monotonic time elapsed since package initialization, so unlike
+// time.Now().UnixNano() it is not affected by adjustments of the wall clock.
+func SampleTimeNanos() int64 {
+	return int64(time.Since(nanosBase))
+}
+
+// SampleTimeIsMonotonic reports whether the timestamps of SampleTime come from a monotonic clock, so that
+// DiffTimeStamps of two timestamps taken in order is never negative, not even across adjustments of the
+// wall clock (e.g. by NTP). On this platform TimeStamp is a time.Time, which is monotonic only if it carries
+// a monotonic clock reading in addition to the wall clock; time.Sub then uses that reading. Go's time.Now
+// provides one on all supported systems, but the type alias hides whether it is present.
+// SampleTimeIsMonotonic checks for it in a fresh timestamp (time.Time.String reports it as "m=±value").
+//
+// If it returns false, time.Sub falls back to the wall clock, and DiffTimeStamps can return negative or
+// wildly wrong durations whenever the wall clock is adjusted between two timestamps. Use AbsDiffTimeStamps
+// in measurement loops then, so that such a sample is at least not negative, and discard implausible samples.
+func SampleTimeIsMonotonic() bool {
+	return strings.Contains(SampleTime().String(), " m=")
+}
@@ -1,7 +1,9 @@
 package rtcompare
 
 import (
+	"math"
 	"runtime"
+	"sync"
 	"testing"
 	"time"
 
@@ -46,11 +48,22 @@ func TestCalcMinTimeSample(t *testing.T) {
 		assert.True(t, minDiff < 100, "calcMinTimeSample should return less than 100 on non-Windows")
 	}
 }
+
+// resetSampleTimePrecision restores the uncalibrated state of the package and returns
+// a function that reinstates the previous precision. It must not be used concurrently.
+func resetSampleTimePrecision() (restore func()) {
+	prev := precision.Load()
+	precisionOnce = sync.Once{}
+	precision.Store(-1)
+	return func() {
+		precisionOnce.Do(func() {})
+		precision.Store(prev)
+	}
+}
+
 func TestGetSampleTimePrecisionSetsAndCaches(t *testing.T) {
-	prev := precision
-	defer func() { precision = prev }()
+	defer resetSampleTimePrecision()()
 
-	precision = int64(-1)
 	p1 := GetSampleTimePrecision()
 	p2 := GetSampleTimePrecision()
 
@@ -64,10 +77,10 @@ func TestGetSampleTimePrecisionSetsAndCaches(t *testing.T) {
 }
 
 func TestGetSampleTimePrecisionRespectsCachedValue(t *testing.T) {
-	prev := precision
-	defer func() { precision = prev }()
+	defer resetSampleTimePrecision()()
 
-	precision = int64(123456)
+	_ = GetSampleTimePrecision()
+	precision.Store(123456)
 	got := GetSampleTimePrecision()
 	assert.Equal(t, int64(123456), got, "GetSampleTimePrecision should return the pre-set precision without recalculation")
 
@@ -75,3 +88,123 @@ func TestGetSampleTimePrecisionRespectsCachedValue(t *testing.T) {
 	got2 := GetSampleTimePrecision()
 	assert.Equal(t, got, got2)
 }
+
+// TestGetSampleTimePrecisionConcurrent lets several goroutines hit GetSampleTimePrecision and
+// RecalibrateSampleTimePrecision simultaneously. Run with -race to detect data races.
+func TestGetSampleTimePrecisionConcurrent(t *testing.T) {
+	defer resetSampleTimePrecision()()
+
+	const goroutines = 8
+	results := make([]int64, goroutines)
+	var wg sync.WaitGroup
+	for i := range goroutines {
+		wg.Go(func() {
+			results[i] = GetSampleTimePrecision()
+		})
+	}
+	wg.Go(func() {
+		_ = RecalibrateSampleTimePrecision()
+	})
+	wg.Wait()
+
+	for i, r := range results {
+		assert.True(t, r > 0, "goroutine %d observed an uncalibrated precision %d", i, r)
+	}
+	assert.True(t, GetSampleTimePrecision() > 0)
+}
+
+func TestPerOpNanos(t *testing.T) {
+	t1 := SampleTime()
+	time.Sleep(10 * time.Millisecond)
+	t2 := SampleTime()
+	diff := DiffTimeStampsFloat(t1, t2)
+
+	assert.Equal(t, diff, PerOpNanos(t1, t2, 1))
+	assert.Equal(t, diff/1000, PerOpNanos(t1, t2, 1000))
+	assert.True(t, math.IsNaN(PerOpNanos(t1, t2, 0)), "expected NaN for ops == 0")
+	assert.True(t, math.IsNaN(PerOpNanos(t1, t2, -5)), "expected NaN for ops < 0")
+}
+
+func TestDiffTimeStampsFloat(t *testing.T) {
+	t1 := SampleTime()
+	time.Sleep(time.Millisecond)
+	t2 := SampleTime()
+	f := DiffTimeStampsFloat(t1, t2)
+	i := DiffTimeStamps(t1, t2)
+	// DiffTimeStamps truncates towards zero, so the float result is at most one nanosecond larger
+	assert.True(t, f >= float64(i) && f < float64(i)+1, "DiffTimeStampsFloat = %v, DiffTimeStamps = %d", f, i)
+	assert.Equal(t, -f, DiffTimeStampsFloat(t2, t1))
+	assert.Equal(t, 0.0, DiffTimeStampsFloat(t1, t1))
+}
+
+func TestAbsDiffTimeStamps(t *testing.T) {
+	t1 := SampleTime()
+	time.Sleep(time.Millisecond)
+	t2 := SampleTime()
+
+	d := DiffTimeStamps(t1, t2)
+	assert.True(t, d > 0)
+	assert.Equal(t, d, AbsDiffTimeStamps(t1, t2))
+	assert.Equal(t, d, AbsDiffTimeStamps(t2, t1), "order of arguments must not matter")
+	assert.Equal(t, -d, DiffTimeStamps(t2, t1))
+	assert.Equal(t, int64(0), AbsDiffTimeStamps(t1, t1))
+}
+
+func TestSampleTimeNanos(t *testing.T) {
+	t1 := SampleTimeNanos()
+	t1a := time.Now()
+	time.Sleep(100 * time.Millisecond)
+	t2 := SampleTimeNanos()
+	t2a := time.Now()
+
+	diff := DiffNanos(t1, t2)
+	diffa := t2a.Sub(t1a)
+	assert.True(t, diff > 0, "timestamps must increase")
+	assert.True(t, FloatsEqualWithTolerance(float64(diff), float64(diffa), 1), "values diverge too much: %v vs. %v", time.Duration(diff), diffa)
+	assert.Equal(t, -diff, DiffNanos(t2, t1))
+}
+
+// BenchmarkSampleTime and BenchmarkSampleTimeNanos compare the per-call overhead of a pair of
+// timestamps plus their difference for the time.Time based and the int64 based timing helpers.
+func BenchmarkSampleTime(b *testing.B) {
+	var sum int64
+	for b.Loop() {
+		t1 := SampleTime()
+		t2 := SampleTime()
+		sum += DiffTimeStamps(t1, t2)
+	}
+	Sink = sum
+}
+
+func BenchmarkSampleTimeNanos(b *testing.B) {
+	var sum int64
+	for b.Loop() {
+		t1 := SampleTimeNanos()
+		t2 := SampleTimeNanos()
+		sum += DiffNanos(t1, t2)
+	}
+	Sink = sum
+}
+
+func TestMeasureTimerOverhead(t *testing.T) {
+	min, median, max := MeasureTimerOverhead(100_000)
+	assert.GreaterOrEqual(t, min, int64(0), "clock must be monotonic")
+	assert.LessOrEqual(t, min, median)
+	assert.LessOrEqual(t, median, max)
+	assert.Less(t, median, int64(10_000), "back-to-back timestamps should be far less than 10µs apart")
+
+	min, median, max = MeasureTimerOverhead(0)
+	assert.Equal(t, [3]int64{0, 0, 0}, [3]int64{min, median, max})
+}
+
+func TestSampleTimeIsMonotonic(t *testing.T) {
+	// Go provides a monotonic clock reading on all supported systems, and QPC is monotonic on Windows
+	assert.True(t, SampleTimeIsMonotonic())
+	if SampleTimeIsMonotonic() {
+		for range 1000 {
+			t1 := SampleTime()
+			t2 := SampleTime()
+			assert.GreaterOrEqual(t, DiffTimeStamps(t1, t2), int64(0))
+		}
+	}
+}
@@ -48,3 +48,27 @@ func DiffTimeStamps(t_earlier, t_later TimeStamp) int64 {
 	result /= qpcFrequency
 	return result
 }
+
+// DiffTimeStampsFloat returns the difference between two timestamps in nanoseconds like DiffTimeStamps, but as
+// a float64 that retains fractions of a nanosecond. On Windows, a QueryPerformanceCounter tick is not a whole
+// number of nanoseconds, so DiffTimeStamps truncates; this matters when dividing by a large number of inner loops.
+func DiffTimeStampsFloat(t_earlier, t_later TimeStamp) float64 {
+	return float64(t_later-t_earlier) * 1e9 / float64(qpcFrequency)
+}
+
+// SampleTimeNanos returns a monotonic timestamp in nanoseconds as a plain int64, an alternative to SampleTime
+// for the tightest loops. Only differences between two values are meaningful (see DiffNanos); they are
+// comparable within the same runtime of a program only.
+// On Windows the value is the QueryPerformanceCounter reading converted to nanoseconds. The conversion is
+// split into whole seconds and the remainder, so it does not overflow for long uptimes.
+func SampleTimeNanos() int64 {
+	qpc := SampleTime()
+	return qpc/qpcFrequency*1_000_000_000 + qpc%qpcFrequency*1_000_000_000/qpcFrequency
+}
+
+// SampleTimeIsMonotonic reports whether the timestamps of SampleTime come from a monotonic clock, so that
+// DiffTimeStamps of two timestamps taken in order is never negative. On Windows, SampleTime reads the
+// QueryPerformanceCounter, which is monotonic by specification, so the result is always true.
+func SampleTimeIsMonotonic() bool {
+	return true
+}
@@ -0,0 +1,95 @@
+package rtcompare
+
+import (
+	"fmt"
+	"math"
+)
+
+// sequentialLookGrowth is the factor by which the number of pairs must grow between two looks of a
+// SequentialComparator. Geometrically spaced looks keep their number logarithmic in the sample size,
+// so the error budget spent per look stays large enough to be resolved by a moderate number of resamples.
+const sequentialLookGrowth = 1.25
+
+// SequentialComparator supports "run until sure" workflows: push pairs of measurements of A and B as they
+// are taken and stop as soon as Confidence reports a decision. It keeps all pushed values and re-runs a
+// bootstrap (as BootstrapConfidence does) at geometrically spaced sample sizes: the first look happens once
+// MinimumDataPoints pairs are available, every further look once the number of pairs has grown by 25%.
+// Between looks, Confidence returns the result of the last look.
+//
+// Peeking caveat: checking a bootstrap confidence after every new measurement and stopping at the first
+// extreme value inflates the error rate far beyond the nominal level, because random fluctuations are
+// eventually bound to cross any fixed bound. SequentialComparator therefore spends its total error budget
+// alpha across looks: look k (k = 1, 2, ...) decides only if the confidence is at least 1 - α_k/2
+// ("A is faster by at least the threshold") or at most α_k/2 ("A is not"), where α_k = alpha/(k(k+1)).
+// Since these α_k sum up to alpha, the probability of a wrong decision stays approximately below alpha no
+// matter how long the measurement runs. The guarantee holds separately for each threshold; it is approximate
+// because the bootstrap confidence itself is only an estimate. Choose resamples large enough to resolve
+// α_k/2, e.g. at least 20/α_k for the looks you expect to need.
+//
+// SequentialComparator is not thread-safe; use one instance per goroutine.
+type SequentialComparator struct {
+	a, b      []float64
+	alpha     float64
+	resamples uint64
+	seed      uint64
+	looks     uint64
+	lookN     int                 // number of pairs evaluated at the last look
+	cache     map[float64]float64 // confidences per threshold computed at the last look
+}
+
+// NewSequentialComparator creates a new SequentialComparator with the total error budget alpha
+// (e.g. 0.05), running `resamples` bootstrap replicates per look. `seed` has the same meaning as
+// `prngSeed` in BootstrapConfidence.
+// The function panics if alpha is not in the open interval (0, 1) or resamples is zero.
+func NewSequentialComparator(alpha float64, resamples, seed uint64) *SequentialComparator {
+	if !(alpha > 0 && alpha < 1) {
+		panic(fmt.Sprintf("alpha must be in (0, 1), got %v", alpha))
+	}
+	if resamples == 0 {
+		panic("sequential comparator needs a positive number of resamples")
+	}
+	return &SequentialComparator{alpha: alpha, resamples: resamples, seed: seed}
+}
+
+// Push adds one measurement of A and one of B.
+func (s *SequentialComparator) Push(a, b float64) {
+	s.a = append(s.a, a)
+	s.b = append(s.b, b)
+}
+
+// Count returns the number of pairs pushed so far.
+func (s *SequentialComparator) Count() uint64 {
+	return uint64(len(s.a))
+}
+
+// Looks returns the number of looks taken so far.
+func (s *SequentialComparator) Looks() uint64 {
+	return s.looks
+}
+
+// Confidence returns the bootstrap confidence that the relative speedup of A over B meets or exceeds
+// threshold, as computed at the most recent look, and whether this confidence is conclusive under the
+// error budget spent up to that look (see SequentialComparator). Calling Confidence takes a new look if
+// enough new pairs have been pushed since the last one; otherwise it is cheap.
+// Returns math.NaN() and false while fewer than MinimumDataPoints pairs have been pushed.
+func (s *SequentialComparator) Confidence(threshold float64) (conf float64, decided bool) {
+	n := len(s.a)
+	if uint64(n) < MinimumDataPoints {
+		return math.NaN(), false
+	}
+	if s.looks == 0 || float64(n) >= float64(s.lookN)*sequentialLookGrowth {
+		s.looks++
+		s.lookN = n
+		s.cache = make(map[float64]float64)
+	}
+	conf, ok := s.cache[threshold]
+	if !ok {
+		// evaluate a threshold first queried between looks on the data of the last look, so it does not
+		// count as an additional look
+		conf = bootstrapConfidence(s.a[:s.lookN], s.b[:s.lookN], []float64{threshold}, s.resamples, s.seed)[threshold]
+		s.cache[threshold] = conf
+	}
+	k := float64(s.looks)
+	bound := s.alpha / (k * (k + 1)) / 2
+	return conf, conf >= 1-bound || conf <= bound
+}
@@ -0,0 +1,79 @@
+package rtcompare
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSequentialComparatorDecidesClearSpeedup(t *testing.T) {
+	rng := NewDPRNG(42)
+	s := NewSequentialComparator(0.05, 5000, 12345)
+	for i := range 1000 {
+		s.Push(50+rng.Float64()*10, 100+rng.Float64()*10)
+		if conf, decided := s.Confidence(0.2); decided {
+			assert.Equal(t, 1.0, conf)
+			assert.Less(t, i, 100, "a twofold speedup should be decided quickly")
+			return
+		}
+	}
+	t.Fatalf("no decision after %d pairs and %d looks", s.Count(), s.Looks())
+}
+
+func TestSequentialComparatorDecidesNoSpeedup(t *testing.T) {
+	rng := NewDPRNG(42)
+	s := NewSequentialComparator(0.05, 5000, 12345)
+	for range 1000 {
+		s.Push(100+rng.Float64()*10, 100+rng.Float64()*10)
+		if conf, decided := s.Confidence(0.5); decided {
+			assert.Equal(t, 0.0, conf, "A is clearly not 50%% faster")
+			return
+		}
+	}
+	t.Fatalf("no decision after %d pairs", s.Count())
+}
+
+func TestSequentialComparatorEqualSamplesUndecided(t *testing.T) {
+	rng := NewDPRNG(7)
+	s := NewSequentialComparator(0.05, 2000, 99)
+	for range 300 {
+		s.Push(100+rng.Float64()*10, 100+rng.Float64()*10)
+		_, decided := s.Confidence(0)
+		assert.False(t, decided, "identical distributions must not be decided at threshold 0")
+	}
+	// looks are spaced geometrically: 11, 14, 18, 23, ... pairs
+	assert.Less(t, s.Looks(), uint64(25))
+}
+
+func TestSequentialComparatorLooks(t *testing.T) {
+	s := NewSequentialComparator(0.05, 100, 1)
+	for i := range int(MinimumDataPoints) - 1 {
+		s.Push(float64(i), float64(i))
+	}
+	conf, decided := s.Confidence(0)
+	assert.True(t, math.IsNaN(conf))
+	assert.False(t, decided)
+	assert.Equal(t, uint64(0), s.Looks())
+
+	s.Push(1, 1)
+	c1, _ := s.Confidence(0)
+	assert.Equal(t, uint64(1), s.Looks())
+	s.Push(1000, 0)
+	c2, _ := s.Confidence(0)
+	assert.Equal(t, uint64(1), s.Looks(), "too few new pairs for another look")
+	assert.Equal(t, c1, c2, "between looks the result of the last look is returned")
+	for range 2 {
+		s.Push(1000, 0)
+	}
+	s.Confidence(0)
+	assert.Equal(t, uint64(2), s.Looks())
+	assert.Equal(t, uint64(14), s.Count())
+}
+
+func TestSequentialComparatorInvalidArguments(t *testing.T) {
+	assert.Panics(t, func() { NewSequentialComparator(0, 100, 1) })
+	assert.Panics(t, func() { NewSequentialComparator(1, 100, 1) })
+	assert.Panics(t, func() { NewSequentialComparator(math.NaN(), 100, 1) })
+	assert.Panics(t, func() { NewSequentialComparator(0.05, 0, 1) })
+}
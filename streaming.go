@@ -0,0 +1,234 @@
+package rtcompare
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// OnlineStats accumulates the arithmetic mean and population variance of a stream of
+// float64 values without storing the values themselves. It implements Welford's
+// numerically stable online algorithm (see
+// https://en.wikipedia.org/wiki/Algorithms_for_calculating_variance#Welford's_online_algorithm).
+// The zero value is ready to use. OnlineStats is not thread-safe; use one instance per goroutine.
+type OnlineStats struct {
+	n    uint64
+	mean float64
+	m2   float64 // sum of squared deviations from the current mean
+}
+
+// Push adds x to the accumulated data.
+func (s *OnlineStats) Push(x float64) {
+	s.n++
+	delta := x - s.mean
+	s.mean += delta / float64(s.n)
+	s.m2 += delta * (x - s.mean)
+}
+
+// Count returns the number of values pushed so far.
+func (s *OnlineStats) Count() uint64 {
+	return s.n
+}
+
+// Mean returns the arithmetic mean of the values pushed so far.
+// Like Statistics, it returns 0 if no values have been pushed.
+func (s *OnlineStats) Mean() float64 {
+	return s.mean
+}
+
+// Variance returns the population variance (sum of squared deviations divided by n)
+// of the values pushed so far, matching the variance returned by Statistics.
+// Like Statistics, it returns -1 if no values have been pushed.
+func (s *OnlineStats) Variance() float64 {
+	if s.n == 0 {
+		return -1
+	}
+	return s.m2 / float64(s.n)
+}
+
+// StdDev returns the population standard deviation (the square root of Variance)
+// of the values pushed so far. Like Statistics, it returns -1 if no values have been pushed.
+func (s *OnlineStats) StdDev() float64 {
+	if s.n == 0 {
+		return -1
+	}
+	return math.Sqrt(s.Variance())
+}
+
+// P2Quantile estimates a single quantile of a stream of float64 values without storing
+// the values, using the P² algorithm by Jain and Chlamtac (see
+// https://www.cse.wustl.edu/~jain/papers/ftp/psqr.pdf). It keeps five markers whose heights
+// approximate the minimum, the p/2, p, (1+p)/2 quantiles, and the maximum, and adjusts them
+// with a piecewise-parabolic interpolation on every Push. Memory and time per Push are constant.
+//
+// The estimate is an approximation: unlike the exact Percentile computed from all stored
+// samples, it can deviate from the true sample quantile, especially for small streams, for
+// extreme quantiles (e.g. P99.9) with few observations in the tail, and for multimodal or
+// non-stationary streams. For smooth distributions and a few thousand observations the
+// error is typically well below the spread of the data in the vicinity of the quantile.
+// P2Quantile is not thread-safe; use one instance per goroutine.
+type P2Quantile struct {
+	p     float64
+	count uint64
+	q     [5]float64 // marker heights
+	n     [5]float64 // actual marker positions (1-based)
+	np    [5]float64 // desired marker positions
+	dn    [5]float64 // increments of the desired marker positions
+}
+
+// NewP2Quantile creates a new P2Quantile estimating the p-quantile (e.g. p = 0.99 for P99).
+// The function panics if p is not in the open interval (0, 1).
+func NewP2Quantile(p float64) *P2Quantile {
+	if !(p > 0 && p < 1) {
+		panic(fmt.Sprintf("quantile must be in (0, 1), got %v", p))
+	}
+	return &P2Quantile{
+		p:  p,
+		dn: [5]float64{0, p / 2, p, (1 + p) / 2, 1},
+	}
+}
+
+// Push adds x to the stream and updates the five markers.
+func (e *P2Quantile) Push(x float64) {
+	if e.count < 5 {
+		e.q[e.count] = x
+		e.count++
+		if e.count == 5 {
+			slices.Sort(e.q[:])
+			p := e.p
+			e.n = [5]float64{1, 2, 3, 4, 5}
+			e.np = [5]float64{1, 1 + 2*p, 1 + 4*p, 3 + 2*p, 5}
+		}
+		return
+	}
+	e.count++
+
+	// find the cell k with q[k] <= x < q[k+1], extending the extreme markers if necessary
+	var k int
+	switch {
+	case x < e.q[0]:
+		e.q[0] = x
+		k = 0
+	case x >= e.q[4]:
+		e.q[4] = x
+		k = 3
+	default:
+		for k = 0; k < 3; k++ {
+			if x < e.q[k+1] {
+				break
+			}
+		}
+	}
+	for i := k + 1; i < 5; i++ {
+		e.n[i]++
+	}
+	for i := range e.np {
+		e.np[i] += e.dn[i]
+	}
+
+	// adjust the heights of the three inner markers if they are off their desired positions
+	for i := 1; i <= 3; i++ {
+		d := e.np[i] - e.n[i]
+		if (d >= 1 && e.n[i+1]-e.n[i] > 1) || (d <= -1 && e.n[i-1]-e.n[i] < -1) {
+			s := 1.0
+			if d < 0 {
+				s = -1.0
+			}
+			qp := e.parabolic(i, s)
+			if e.q[i-1] < qp && qp < e.q[i+1] {
+				e.q[i] = qp
+			} else {
+				e.q[i] = e.linear(i, s)
+			}
+			e.n[i] += s
+		}
+	}
+}
+
+// parabolic returns the piecewise-parabolic (P²) prediction for marker i moved by s (±1).
+func (e *P2Quantile) parabolic(i int, s float64) float64 {
+	q, n := &e.q, &e.n
+	return q[i] + s/(n[i+1]-n[i-1])*
+		((n[i]-n[i-1]+s)*(q[i+1]-q[i])/(n[i+1]-n[i])+
+			(n[i+1]-n[i]-s)*(q[i]-q[i-1])/(n[i]-n[i-1]))
+}
+
+// linear returns the linear prediction for marker i moved by s (±1).
+func (e *P2Quantile) linear(i int, s float64) float64 {
+	j := i + int(s)
+	return e.q[i] + s*(e.q[j]-e.q[i])/(e.n[j]-e.n[i])
+}
+
+// Count returns the number of values pushed so far.
+func (e *P2Quantile) Count() uint64 {
+	return e.count
+}
+
+// Value returns the current estimate of the quantile.
+// Until five values have been pushed, it returns the exact quantile of the values seen so far
+// (the element at the rounded rank p*(n-1) of the sorted values). It returns math.NaN() if no
+// values have been pushed.
+func (e *P2Quantile) Value() float64 {
+	if e.count == 0 {
+		return math.NaN()
+	}
+	if e.count < 5 {
+		sorted := make([]float64, e.count)
+		copy(sorted, e.q[:e.count])
+		slices.Sort(sorted)
+		return sorted[int(math.Round(e.p*float64(e.count-1)))]
+	}
+	return e.q[2]
+}
+
+// Reservoir keeps a bounded, uniformly random subsample of a stream of float64 values using
+// Vitter's algorithm R (see https://en.wikipedia.org/wiki/Reservoir_sampling#Simple:_Algorithm_R).
+// After n values have been pushed, every one of them is contained in the reservoir with the
+// same probability capacity/n. With a *DPRNG as RandSource, the content of the reservoir is
+// deterministic for a given seed and input order.
+// Reservoir is not thread-safe; use one instance per goroutine.
+type Reservoir struct {
+	samples []float64
+	seen    uint64
+	rng     RandSource
+}
+
+// NewReservoir creates a new Reservoir holding at most capacity values and drawing its
+// random replacement decisions from rng.
+// The function panics if capacity is not positive or rng is nil.
+func NewReservoir(capacity int, rng RandSource) *Reservoir {
+	if capacity <= 0 {
+		panic(fmt.Sprintf("reservoir capacity must be positive, got %d", capacity))
+	}
+	if rng == nil {
+		panic("reservoir needs a non-nil RandSource")
+	}
+	return &Reservoir{samples: make([]float64, 0, capacity), rng: rng}
+}
+
+// Push offers x to the reservoir. The first capacity values are always kept; afterwards
+// the n-th value replaces a random element of the reservoir with probability capacity/n.
+func (r *Reservoir) Push(x float64) {
+	r.seen++
+	if len(r.samples) < cap(r.samples) {
+		r.samples = append(r.samples, x)
+		return
+	}
+	j := uint64n(r.rng, r.seen)
+	if j < uint64(len(r.samples)) {
+		r.samples[j] = x
+	}
+}
+
+// Count returns the number of values pushed so far (including those not kept).
+func (r *Reservoir) Count() uint64 {
+	return r.seen
+}
+
+// Samples returns a copy of the values currently held in the reservoir.
+// The result can be passed to CompareSamples directly.
+func (r *Reservoir) Samples() []float64 {
+	result := make([]float64, len(r.samples))
+	copy(result, r.samples)
+	return result
+}
@@ -0,0 +1,157 @@
+package rtcompare
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnlineStatsEmpty(t *testing.T) {
+	var s OnlineStats
+	assert.Equal(t, uint64(0), s.Count())
+	assert.Equal(t, 0.0, s.Mean())
+	assert.Equal(t, -1.0, s.Variance())
+	assert.Equal(t, -1.0, s.StdDev())
+}
+
+func TestOnlineStatsMatchesStatistics(t *testing.T) {
+	testCases := [][]float64{
+		{1},
+		{1, 2, 3},
+		{1, 2, 3, 4},
+		{1, 1, 1, 1},
+		{1.5, 2.5, 3.5},
+		{3, 53, 512, 11, 75, 201, 335},
+	}
+	rng := NewDPRNG(0x1234567890ABCDEF)
+	large := make([]float64, 100_000)
+	for i := range large {
+		large[i] = 1e9 + rng.Float64()*1000
+	}
+	testCases = append(testCases, large)
+
+	for _, data := range testCases {
+		var s OnlineStats
+		for _, x := range data {
+			s.Push(x)
+		}
+		mean, variance, stddev := Statistics(data)
+		assert.Equal(t, uint64(len(data)), s.Count())
+		assert.True(t, FloatsEqualWithTolerance(mean, s.Mean(), 1e-9), "mean: expected %v, got %v", mean, s.Mean())
+		assert.True(t, FloatsEqualWithTolerance(variance, s.Variance(), 1e-6), "variance: expected %v, got %v", variance, s.Variance())
+		assert.True(t, FloatsEqualWithTolerance(stddev, s.StdDev(), 1e-6), "stddev: expected %v, got %v", stddev, s.StdDev())
+	}
+}
+
+func TestP2QuantileInvalidP(t *testing.T) {
+	for _, p := range []float64{0, 1, -0.5, 1.5, math.NaN()} {
+		assert.Panics(t, func() { NewP2Quantile(p) }, "expected panic for p=%v", p)
+	}
+}
+
+func TestP2QuantileFewValues(t *testing.T) {
+	e := NewP2Quantile(0.5)
+	assert.True(t, math.IsNaN(e.Value()), "expected NaN for empty stream")
+	for _, x := range []float64{4, 1, 3} {
+		e.Push(x)
+	}
+	assert.Equal(t, 3.0, e.Value())
+	assert.Equal(t, uint64(3), e.Count())
+}
+
+func TestP2QuantileUniformStream(t *testing.T) {
+	const n = 100_000
+	for _, p := range []float64{0.1, 0.5, 0.9, 0.99} {
+		rng := NewDPRNG(0x1234567890ABCDEF)
+		e := NewP2Quantile(p)
+		data := make([]float64, n)
+		for i := range data {
+			data[i] = rng.Float64() * 1000
+			e.Push(data[i])
+		}
+		slices.Sort(data)
+		exact := data[int(p*float64(n-1))]
+		got := e.Value()
+		// for uniformly distributed data an error of 0.5% of the range is plenty
+		if math.Abs(got-exact) > 5 {
+			t.Errorf("p=%.2f: estimate %.3f too far from exact quantile %.3f", p, got, exact)
+		}
+	}
+}
+
+func TestP2QuantileMonotoneMarkers(t *testing.T) {
+	rng := NewDPRNG(42)
+	e := NewP2Quantile(0.95)
+	for range 10_000 {
+		e.Push(rng.Float64())
+		if e.Count() >= 5 {
+			for i := 1; i < 5; i++ {
+				if e.q[i] < e.q[i-1] {
+					t.Fatalf("marker heights not monotone after %d values: %v", e.Count(), e.q)
+				}
+			}
+		}
+	}
+}
+
+func TestReservoirFewerValuesThanCapacity(t *testing.T) {
+	rng := NewDPRNG(42)
+	r := NewReservoir(10, &rng)
+	for i := range 5 {
+		r.Push(float64(i))
+	}
+	assert.Equal(t, []float64{0, 1, 2, 3, 4}, r.Samples())
+	assert.Equal(t, uint64(5), r.Count())
+}
+
+func TestReservoirDeterministic(t *testing.T) {
+	rng1 := NewDPRNG(0x1234567890ABCDEF)
+	rng2 := NewDPRNG(0x1234567890ABCDEF)
+	r1 := NewReservoir(50, &rng1)
+	r2 := NewReservoir(50, &rng2)
+	for i := range 10_000 {
+		r1.Push(float64(i))
+		r2.Push(float64(i))
+	}
+	assert.Equal(t, r1.Samples(), r2.Samples())
+	assert.Len(t, r1.Samples(), 50)
+}
+
+func TestReservoirSamplesIsCopy(t *testing.T) {
+	r := NewReservoir(3, NewCPRNG(64))
+	r.Push(1)
+	s := r.Samples()
+	s[0] = 99
+	assert.Equal(t, []float64{1}, r.Samples())
+}
+
+func TestReservoirUniformInclusion(t *testing.T) {
+	const streamLen = 100
+	const capacity = 10
+	const runs = 100_000
+	rng := NewDPRNG(0xDEADBEEFCAFEBABE)
+	counts := make([]int, streamLen)
+	for range runs {
+		r := NewReservoir(capacity, &rng)
+		for i := range streamLen {
+			r.Push(float64(i))
+		}
+		for _, v := range r.Samples() {
+			counts[int(v)]++
+		}
+	}
+	expected := float64(runs) * capacity / streamLen
+	x2 := chiSquare(counts, expected)
+	p := chiSquarePValue(x2, streamLen-1)
+	if p < 0.001 {
+		t.Fatalf("reservoir inclusion not uniform: χ²=%.3f p=%.5f", x2, p)
+	}
+}
+
+func TestReservoirInvalidArguments(t *testing.T) {
+	rng := NewDPRNG(1)
+	assert.Panics(t, func() { NewReservoir(0, &rng) })
+	assert.Panics(t, func() { NewReservoir(1, nil) })
+}
@@ -0,0 +1,177 @@
+package rtcompare
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// DefaultTDigestCompression is a compression for NewTDigest that keeps about 700 centroids
+// for a million values and estimates tail quantiles such as P99.9 of heavy-tailed latencies
+// typically within a fraction of a percent of the exact sample quantile.
+const DefaultTDigestCompression = 100
+
+// TDigest estimates arbitrary quantiles of a stream of float64 values with bounded memory,
+// using the merging t-digest by Dunning and Ertl (see https://arxiv.org/abs/1902.04023).
+// The values are summarized in clusters (centroids) of a mean and a weight. Clusters near
+// the median may hold many values, while clusters in the tails are kept small, so extreme
+// quantiles (e.g. P99 or P99.9 of heavy-tailed latencies) are estimated much more accurately
+// than with a fixed-bin histogram. The minimum and maximum are tracked exactly.
+//
+// Unlike P2Quantile, a TDigest answers any quantile after the fact, and two digests can be
+// merged, e.g. to combine the digests of several goroutines or machines.
+// TDigest is not thread-safe; use one instance per goroutine and Merge them afterwards.
+type TDigest struct {
+	compression float64
+	centroids   []tdCentroid // sorted by mean
+	buffer      []tdCentroid // unsorted, not yet merged into centroids
+	count       float64
+	min, max    float64
+}
+
+type tdCentroid struct {
+	mean   float64
+	weight float64
+}
+
+// NewTDigest creates a new, empty TDigest. The compression δ trades accuracy for memory:
+// a centroid around the quantile q summarizes at most 4·n·q·(1−q)/δ of the n values, so the
+// digest keeps about δ/2·ln(n) centroids (e.g. about 700 for a million values at δ = 100)
+// plus a buffer of 5δ values not yet merged. Memory thus grows only logarithmically with the
+// length of the stream. Higher values of δ give more accurate quantiles at the cost of memory
+// and time; the rank error shrinks roughly proportionally to 1/δ. Use DefaultTDigestCompression
+// if unsure.
+// The function panics if compression is not a finite number of at least 10.
+func NewTDigest(compression float64) *TDigest {
+	if !(compression >= 10) || math.IsInf(compression, 1) {
+		panic(fmt.Sprintf("t-digest compression must be a finite number >= 10, got %v", compression))
+	}
+	return &TDigest{
+		compression: compression,
+		buffer:      make([]tdCentroid, 0, int(5*compression)),
+		min:         math.Inf(1),
+		max:         math.Inf(-1),
+	}
+}
+
+// Add adds x to the digest. NaN values are ignored.
+func (t *TDigest) Add(x float64) {
+	t.add(x, 1)
+}
+
+func (t *TDigest) add(mean, weight float64) {
+	if math.IsNaN(mean) {
+		return
+	}
+	if len(t.buffer) == cap(t.buffer) {
+		t.compress()
+	}
+	t.buffer = append(t.buffer, tdCentroid{mean: mean, weight: weight})
+	t.count += weight
+	t.min = min(t.min, mean)
+	t.max = max(t.max, mean)
+}
+
+// Merge adds all values summarized by other to t. other is not modified (apart from merging
+// its own buffered values). Merging digests is associative up to the approximation error,
+// so the result is close to the digest of the concatenated streams.
+func (t *TDigest) Merge(other *TDigest) {
+	if other == nil || other.count == 0 {
+		return
+	}
+	other.compress()
+	centroids := slices.Clone(other.centroids)
+	for _, c := range centroids {
+		t.add(c.mean, c.weight)
+	}
+	// the extreme values may have been averaged into centroids of other
+	t.min = min(t.min, other.min)
+	t.max = max(t.max, other.max)
+}
+
+// Count returns the number of values added so far (including those added via Merge).
+func (t *TDigest) Count() uint64 {
+	return uint64(t.count)
+}
+
+// Centroids returns the number of clusters currently kept after merging all buffered values.
+// It grows only logarithmically with Count (see NewTDigest).
+func (t *TDigest) Centroids() int {
+	t.compress()
+	return len(t.centroids)
+}
+
+// Quantile returns the estimated q-quantile (e.g. q = 0.999 for P99.9) of the values added
+// so far. It returns the exact minimum for q <= 0 and the exact maximum for q >= 1. In between,
+// the estimate interpolates linearly between the means of neighboring centroids. It returns
+// math.NaN() if no values have been added or q is NaN.
+func (t *TDigest) Quantile(q float64) float64 {
+	t.compress()
+	if len(t.centroids) == 0 || math.IsNaN(q) {
+		return math.NaN()
+	}
+	if q <= 0 {
+		return t.min
+	}
+	if q >= 1 {
+		return t.max
+	}
+	cs := t.centroids
+	index := q * t.count
+
+	// left tail: interpolate between the minimum and the center of the first centroid
+	first := cs[0]
+	if index < first.weight/2 {
+		return t.min + (first.mean-t.min)*index/(first.weight/2)
+	}
+	cumulative := first.weight / 2 // rank of the center of cs[i]
+	for i := 0; i < len(cs)-1; i++ {
+		step := (cs[i].weight + cs[i+1].weight) / 2
+		if cumulative+step > index {
+			z := (index - cumulative) / step
+			return cs[i].mean + z*(cs[i+1].mean-cs[i].mean)
+		}
+		cumulative += step
+	}
+	// right tail: interpolate between the center of the last centroid and the maximum
+	last := cs[len(cs)-1]
+	z := min((index-cumulative)/(last.weight/2), 1)
+	return last.mean + z*(t.max-last.mean)
+}
+
+// compress merges the buffered values into the centroids. Neighboring clusters are combined
+// as long as the combined weight stays within the size bound 4·n·q·(1−q)/δ at the quantile q
+// of the combined cluster, which keeps the clusters in the tails small.
+func (t *TDigest) compress() {
+	if len(t.buffer) == 0 {
+		return
+	}
+	all := append(t.buffer, t.centroids...)
+	slices.SortFunc(all, func(a, b tdCentroid) int {
+		switch {
+		case a.mean < b.mean:
+			return -1
+		case a.mean > b.mean:
+			return 1
+		}
+		return 0
+	})
+
+	merged := make([]tdCentroid, 0, len(t.centroids)+1)
+	current := all[0]
+	weightSoFar := 0.0
+	for _, c := range all[1:] {
+		proposed := current.weight + c.weight
+		q := (weightSoFar + proposed/2) / t.count
+		if proposed <= 4*t.count*q*(1-q)/t.compression {
+			current.weight = proposed
+			current.mean += (c.mean - current.mean) * c.weight / current.weight
+			continue
+		}
+		weightSoFar += current.weight
+		merged = append(merged, current)
+		current = c
+	}
+	t.centroids = append(merged, current)
+	t.buffer = t.buffer[:0]
+}
@@ -0,0 +1,102 @@
+package rtcompare
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTDigestInvalidCompression(t *testing.T) {
+	for _, c := range []float64{0, 5, -100, math.NaN(), math.Inf(1)} {
+		assert.Panics(t, func() { NewTDigest(c) }, "expected panic for compression=%v", c)
+	}
+}
+
+func TestTDigestEmpty(t *testing.T) {
+	d := NewTDigest(DefaultTDigestCompression)
+	assert.True(t, math.IsNaN(d.Quantile(0.5)), "expected NaN for empty digest")
+	assert.Equal(t, uint64(0), d.Count())
+	d.Add(math.NaN())
+	assert.Equal(t, uint64(0), d.Count(), "NaN values must be ignored")
+}
+
+func TestTDigestFewValuesExact(t *testing.T) {
+	d := NewTDigest(DefaultTDigestCompression)
+	for _, x := range []float64{5, 1, 4, 2, 3} {
+		d.Add(x)
+	}
+	assert.Equal(t, uint64(5), d.Count())
+	assert.Equal(t, 1.0, d.Quantile(0))
+	assert.Equal(t, 3.0, d.Quantile(0.5))
+	assert.Equal(t, 5.0, d.Quantile(1))
+}
+
+// heavyTailed returns n log-normally distributed values, similar to latencies with rare stalls.
+func heavyTailed(n int, seed uint64) []float64 {
+	rng := NewDPRNG(seed)
+	data := make([]float64, n)
+	for i := range data {
+		u1, u2 := rng.Float64OpenOpen(), rng.Float64()
+		z := math.Sqrt(-2*math.Log(u1)) * math.Cos(2*math.Pi*u2)
+		data[i] = 1000 * math.Exp(1.5*z)
+	}
+	return data
+}
+
+func TestTDigestTailQuantiles(t *testing.T) {
+	const n = 200_000
+	data := heavyTailed(n, 0x1234567890ABCDEF)
+	d := NewTDigest(DefaultTDigestCompression)
+	for _, x := range data {
+		d.Add(x)
+	}
+	sorted := slices.Clone(data)
+	slices.Sort(sorted)
+	for _, q := range []float64{0.5, 0.9, 0.99, 0.999} {
+		exact := sorted[int(q*float64(n-1))]
+		got := d.Quantile(q)
+		assert.InEpsilon(t, exact, got, 0.005, "q=%v: estimate %.1f vs exact %.1f", q, got, exact)
+	}
+	assert.Equal(t, sorted[0], d.Quantile(0))
+	assert.Equal(t, sorted[n-1], d.Quantile(1))
+	assert.LessOrEqual(t, float64(d.Centroids()), DefaultTDigestCompression/2*math.Log(n)*1.2, "memory must stay bounded")
+}
+
+func TestTDigestMonotone(t *testing.T) {
+	d := NewTDigest(50)
+	for _, x := range heavyTailed(20_000, 42) {
+		d.Add(x)
+	}
+	prev := d.Quantile(0)
+	for q := 0.001; q <= 1; q += 0.001 {
+		v := d.Quantile(q)
+		if v < prev {
+			t.Fatalf("quantiles not monotone at q=%v: %v < %v", q, v, prev)
+		}
+		prev = v
+	}
+}
+
+func TestTDigestMerge(t *testing.T) {
+	data := heavyTailed(100_000, 7)
+	whole := NewTDigest(DefaultTDigestCompression)
+	parts := []*TDigest{NewTDigest(DefaultTDigestCompression), NewTDigest(DefaultTDigestCompression), NewTDigest(DefaultTDigestCompression)}
+	for i, x := range data {
+		whole.Add(x)
+		parts[i%len(parts)].Add(x)
+	}
+	merged := NewTDigest(DefaultTDigestCompression)
+	for _, p := range parts {
+		merged.Merge(p)
+	}
+	merged.Merge(nil)
+	assert.Equal(t, whole.Count(), merged.Count())
+	assert.Equal(t, whole.Quantile(0), merged.Quantile(0))
+	assert.Equal(t, whole.Quantile(1), merged.Quantile(1))
+	for _, q := range []float64{0.5, 0.99, 0.999} {
+		assert.InEpsilon(t, whole.Quantile(q), merged.Quantile(q), 0.005, "q=%v", q)
+	}
+	assert.Equal(t, uint64(len(data)/len(parts)+1), parts[0].Count(), "Merge must not change its argument")
+}
@@ -0,0 +1,57 @@
+package rtcompare
+
+// Outcome is the overall classification of a comparison returned by Verdict.
+type Outcome string
+
+const (
+	// OutcomeFaster means that A is faster than B by more than some positive threshold with the requested confidence.
+	OutcomeFaster Outcome = "faster"
+	// OutcomeSlower means that A is slower than B (by more than the magnitude of some threshold <= 0) with the requested confidence.
+	OutcomeSlower Outcome = "slower"
+	// OutcomeNoChange means that, with the requested confidence, A is neither slower than B beyond some
+	// threshold <= 0 nor faster than B beyond some threshold > 0.
+	OutcomeNoChange Outcome = "no change"
+	// OutcomeInconclusive means that the results do not support any of the other outcomes.
+	OutcomeInconclusive Outcome = "inconclusive"
+)
+
+// Verdict condenses the results of CompareSamples into a single Outcome, e.g. for a CI pass/fail signal.
+// Recall that the confidence for a threshold t is the estimated probability that the relative speedup of A over B
+// is at least t, so 1 - confidence is the probability that it is below t. With c = confidenceThreshold:
+//   - OutcomeFaster if the highest threshold with a confidence of at least c is positive.
+//   - OutcomeSlower if some threshold t <= 0 has a confidence of at most 1 - c, i.e. A is slower than B by more
+//     than |t| with a confidence of at least c. Include 0 and negative thresholds (e.g. -0.05) in relativeGains
+//     to detect slowdowns.
+//   - OutcomeNoChange if some threshold <= 0 has a confidence of at least c and some threshold > 0 has a
+//     confidence of at most 1 - c, i.e. the speedup is bounded on both sides.
+//   - OutcomeInconclusive otherwise, as well as for empty results or if c is not in the interval (0.5, 1].
+//
+// confidenceThreshold may be given as a fraction or as a percentage (0.95 or 95, see MeanConfidenceInterval).
+// Thresholds are considered independently of their order in results; NaN confidences are ignored.
+func Verdict(results []RTcomparisonResult, confidenceThreshold float64) Outcome {
+	c, err := normalizeConfidence(confidenceThreshold)
+	if err != nil || !(c > 0.5 && c <= 1) {
+		return OutcomeInconclusive
+	}
+	faster, slower, notSlower, notFaster := false, false, false, false
+	for _, r := range results {
+		t, conf := r.RelativeSpeedupSampleAvsSampleB, r.Confidence
+		if t > 0 {
+			faster = faster || conf >= c
+			notFaster = notFaster || 1-conf >= c
+		} else {
+			slower = slower || 1-conf >= c
+			notSlower = notSlower || conf >= c
+		}
+	}
+	switch {
+	case faster:
+		return OutcomeFaster
+	case slower:
+		return OutcomeSlower
+	case notSlower && notFaster:
+		return OutcomeNoChange
+	default:
+		return OutcomeInconclusive
+	}
+}
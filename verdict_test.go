@@ -0,0 +1,64 @@
+package rtcompare
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func verdictResults(pairs ...float64) []RTcomparisonResult {
+	var r []RTcomparisonResult
+	for i := 0; i+1 < len(pairs); i += 2 {
+		r = append(r, RTcomparisonResult{RelativeSpeedupSampleAvsSampleB: pairs[i], Confidence: pairs[i+1], Estimator: EstimatorMedian})
+	}
+	return r
+}
+
+func TestVerdictClassification(t *testing.T) {
+	testCases := []struct {
+		name    string
+		results []RTcomparisonResult
+		want    Outcome
+	}{
+		{"faster", verdictResults(0, 1, 0.1, 0.99, 0.2, 0.4), OutcomeFaster},
+		{"faster unordered", verdictResults(0.2, 0.4, 0.1, 0.99, 0, 1), OutcomeFaster},
+		{"slower at zero", verdictResults(0, 0.01, 0.1, 0), OutcomeSlower},
+		{"slower by more than 5%", verdictResults(-0.05, 0.02, 0, 0, 0.1, 0), OutcomeSlower},
+		{"no change", verdictResults(-0.05, 0.99, 0, 0.5, 0.05, 0.01), OutcomeNoChange},
+		{"inconclusive", verdictResults(-0.05, 0.9, 0, 0.5, 0.05, 0.2), OutcomeInconclusive},
+		{"only positive thresholds", verdictResults(0.1, 0.5, 0.2, 0.2), OutcomeInconclusive},
+		{"NaN confidences", verdictResults(0, math.NaN(), 0.1, math.NaN()), OutcomeInconclusive},
+		{"empty", nil, OutcomeInconclusive},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Verdict(tc.results, 0.95))
+		})
+	}
+}
+
+func TestVerdictInvalidConfidenceThreshold(t *testing.T) {
+	r := verdictResults(0, 1, 0.1, 1)
+	for _, c := range []float64{0.5, 0, -1, 1.01, math.NaN()} {
+		assert.Equal(t, OutcomeInconclusive, Verdict(r, c), "confidenceThreshold %v", c)
+	}
+	assert.Equal(t, OutcomeFaster, Verdict(r, 1))
+}
+
+func TestVerdictWithCompareSamples(t *testing.T) {
+	rng := NewDPRNG(42)
+	fast := make([]float64, 200)
+	slow := make([]float64, 200)
+	for i := range fast {
+		fast[i] = 100 + rng.Float64()*10
+		slow[i] = 150 + rng.Float64()*10
+	}
+	thresholds := []float64{-0.1, 0, 0.1, 0.2}
+	res, err := CompareSamples(fast, slow, thresholds, 2000)
+	assert.NoError(t, err)
+	assert.Equal(t, OutcomeFaster, Verdict(res, 0.95))
+	res, err = CompareSamples(slow, fast, thresholds, 2000)
+	assert.NoError(t, err)
+	assert.Equal(t, OutcomeSlower, Verdict(res, 0.95))
+}
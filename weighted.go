@@ -0,0 +1,199 @@
+package rtcompare
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// aliasTable draws indices in [0, n) with probabilities proportional to a set of weights in O(1)
+// per draw, using Vose's alias method (see https://www.keithschwarz.com/darts-dice-coins/).
+type aliasTable struct {
+	prob  []float64 // probability of keeping column i
+	alias []uint64  // alternative index of column i
+}
+
+// newAliasTable builds an aliasTable for the given weights. The weights must be finite,
+// non-negative and have a positive sum; this is not checked here.
+func newAliasTable(weights []float64) aliasTable {
+	n := len(weights)
+	var total float64
+	for _, w := range weights {
+		total += w
+	}
+	t := aliasTable{prob: make([]float64, n), alias: make([]uint64, n)}
+	scaled := make([]float64, n)
+	small := make([]uint64, 0, n)
+	large := make([]uint64, 0, n)
+	for i, w := range weights {
+		scaled[i] = w * float64(n) / total
+		if scaled[i] < 1 {
+			small = append(small, uint64(i))
+		} else {
+			large = append(large, uint64(i))
+		}
+	}
+	for len(small) > 0 && len(large) > 0 {
+		s := small[len(small)-1]
+		small = small[:len(small)-1]
+		l := large[len(large)-1]
+		large = large[:len(large)-1]
+		t.prob[s] = scaled[s]
+		t.alias[s] = l
+		scaled[l] = scaled[l] + scaled[s] - 1
+		if scaled[l] < 1 {
+			small = append(small, l)
+		} else {
+			large = append(large, l)
+		}
+	}
+	// the remaining columns are full (up to rounding errors)
+	for _, i := range slices.Concat(small, large) {
+		t.prob[i] = 1
+		t.alias[i] = i
+	}
+	return t
+}
+
+// draw returns a random index distributed according to the weights of the table.
+func (t aliasTable) draw(rng RandSource) uint64 {
+	i := uint64n(rng, uint64(len(t.prob)))
+	u := float64(rng.Uint64()>>11) * (1.0 / (1 << 53))
+	if u < t.prob[i] {
+		return i
+	}
+	return t.alias[i]
+}
+
+// WeightedMedian returns the weighted median of values, e.g. to summarize importance-weighted measurements:
+// after sorting the values, it is the first value at which the cumulative weight exceeds half of the total
+// weight, i.e. the smallest value v such that the values less than or equal to v carry more than half of the
+// total weight. Like QuickMedian, it returns the upper of the two middle values for equal weights and an even
+// number of values. Only the ratios of the weights matter; a zero weight excludes a value.
+// CompareSamplesWeighted reports the relative difference of the weighted medians of A and B as ObservedDelta.
+//
+// Returns math.NaN() if values is empty or contains a NaN, or if the weights are invalid: weights must have the
+// same length as values and be finite and non-negative with a positive sum (so all-zero weights yield NaN).
+// The inputs are not modified.
+func WeightedMedian(values, weights []float64) float64 {
+	if len(values) == 0 || hasNaN(values) || validateWeights("values", values, weights) != nil {
+		return math.NaN()
+	}
+	return weightedMedian(values, weights)
+}
+
+// weightedMedian returns the (upper) weighted median of values: the smallest value v such that the
+// values less than or equal to v carry more than half of the total weight. For equal weights this is the
+// element returned by QuickMedian. The weights must be valid (see validateWeights); this is not checked here.
+func weightedMedian(values, weights []float64) float64 {
+	idx := make([]int, len(values))
+	var total float64
+	for i, w := range weights {
+		idx[i] = i
+		total += w
+	}
+	slices.SortFunc(idx, func(i, j int) int { return cmp.Compare(values[i], values[j]) })
+	var cumulative float64
+	for _, i := range idx {
+		cumulative += weights[i]
+		if cumulative > total/2 {
+			return values[i]
+		}
+	}
+	return values[idx[len(idx)-1]] // only reached through rounding errors
+}
+
+// validateWeights checks that weights has the same length as values and contains only
+// finite, non-negative values with a positive sum.
+func validateWeights(name string, values, weights []float64) error {
+	if len(values) != len(weights) {
+		return fmt.Errorf("%w: %s has %d values but %d weights", ErrInvalidWeights, name, len(values), len(weights))
+	}
+	var total float64
+	for i, w := range weights {
+		if math.IsNaN(w) || math.IsInf(w, 0) || w < 0 {
+			return fmt.Errorf("%w: %s has weight %v at index %d, weights must be finite and non-negative", ErrInvalidWeights, name, w, i)
+		}
+		total += w
+	}
+	if !(total > 0) || math.IsInf(total, 0) {
+		return fmt.Errorf("%w: weights of %s must have a positive, finite sum", ErrInvalidWeights, name)
+	}
+	return nil
+}
+
+// CompareSamplesWeighted is a variant of CompareSamples for measurements of differing trustworthiness.
+// Each bootstrap replicate draws len(A) values from A and len(B) values from B with replacement, where
+// the probability of drawing a value is proportional to its weight (instead of uniform as in
+// CompareSamples). Measurements with a higher weight, e.g. timings taken over longer inner loops with
+// lower quantization noise, thus have more influence on the medians without discarding the others.
+//
+// The weights are normalized internally, so only their ratios matter; they must be finite, non-negative
+// and have a positive sum, and weightsA/weightsB must have the same length as A/B. Values drawn with equal
+// weights behave like CompareSamples. `resamples` and `seed` have the same meaning as `resamples` and
+// `prngSeed` in BootstrapConfidence. An error wrapping ErrInvalidWeights is returned for invalid weights,
+// and an error wrapping ErrTooFewDataPoints if either input contains fewer than `MinimumDataPoints` values.
+// The ObservedDelta of the results is computed from the weighted medians of A and B.
+func CompareSamplesWeighted(A []float64, weightsA []float64, B []float64, weightsB []float64, relativeGains []float64, resamples, seed uint64) (result []RTcomparisonResult, err error) {
+	if uint64(len(A)) < MinimumDataPoints || uint64(len(B)) < MinimumDataPoints {
+		return []RTcomparisonResult{}, fmt.Errorf("%w: need at least %d measurements for each input", ErrTooFewDataPoints, MinimumDataPoints)
+	}
+	if err := validateWeights("A", A, weightsA); err != nil {
+		return []RTcomparisonResult{}, err
+	}
+	if err := validateWeights("B", B, weightsB); err != nil {
+		return []RTcomparisonResult{}, err
+	}
+	if len(relativeGains) == 0 {
+		relativeGains = []float64{0.0}
+	}
+	slices.Sort(relativeGains)
+
+	observed := relativeDelta(weightedMedian(A, weightsA), weightedMedian(B, weightsB))
+	tableA := newAliasTable(weightsA)
+	tableB := newAliasTable(weightsB)
+	var cprng *CPRNG
+	if seed == 0 {
+		cprng = NewCPRNG(8192)
+		defer cprng.Release()
+	}
+	sampleA := make([]float64, len(A))
+	sampleB := make([]float64, len(B))
+	counts := make([]uint64, len(relativeGains))
+	for i := range resamples {
+		var rngA, rngB RandSource = cprng, cprng
+		if seed != 0 {
+			seedA, seedB := replicateSeeds(seed, i)
+			dA, dB := NewDPRNG(seedA), NewDPRNG(seedB)
+			rngA, rngB = &dA, &dB
+		}
+		for j := range sampleA {
+			sampleA[j] = A[tableA.draw(rngA)]
+		}
+		for j := range sampleB {
+			sampleB[j] = B[tableB.draw(rngB)]
+		}
+		delta := relativeDelta(QuickMedian(sampleA), QuickMedian(sampleB))
+		for k, threshold := range relativeGains {
+			if delta >= threshold {
+				counts[k]++
+			}
+		}
+	}
+
+	for k, t := range relativeGains {
+		confidence := math.NaN()
+		if resamples > 0 {
+			confidence = float64(counts[k]) / float64(resamples)
+		}
+		result = append(result, RTcomparisonResult{
+			RelativeSpeedupSampleAvsSampleB: t,
+			Confidence:                      confidence,
+			Estimator:                       EstimatorMedian,
+			ObservedDelta:                   observed,
+			ConfidenceLowerBound:            wilsonLowerBound(confidence, resamples),
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,111 @@
+package rtcompare
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAliasTableDistribution(t *testing.T) {
+	weights := []float64{1, 2, 3, 0, 4}
+	table := newAliasTable(weights)
+	rng := NewDPRNG(0x1234567890ABCDEF)
+	const draws = 1_000_000
+	counts := make([]int, len(weights))
+	for range draws {
+		counts[table.draw(&rng)]++
+	}
+	assert.Equal(t, 0, counts[3], "zero-weight index must never be drawn")
+	for i, w := range weights {
+		expected := w / 10 * draws
+		assert.InDelta(t, expected, float64(counts[i]), 0.01*draws, "index %d", i)
+	}
+}
+
+func TestCompareSamplesWeightedEqualWeights(t *testing.T) {
+	A := []float64{100, 101, 99, 98, 102, 103, 97, 104, 96, 105, 100}
+	B := []float64{120, 118, 122, 119, 121, 117, 123, 116, 124, 115, 119}
+	w := make([]float64, len(A))
+	for i := range w {
+		w[i] = 1
+	}
+	results, err := CompareSamplesWeighted(A, w, B, w, []float64{0.1, 0.0}, 2000, 42)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, 0.0, results[0].RelativeSpeedupSampleAvsSampleB, "thresholds must be sorted")
+	assert.True(t, results[0].Confidence > 0.99)
+
+	again, _ := CompareSamplesWeighted(A, w, B, w, []float64{0.1, 0.0}, 2000, 42)
+	assert.Equal(t, results, again, "results must be deterministic for a fixed seed")
+}
+
+func TestCompareSamplesWeightedShiftsInfluence(t *testing.T) {
+	// A contains a cluster of fast and a cluster of slow measurements.
+	// Weighting the fast cluster up must increase the confidence that A is faster than B.
+	A := []float64{80, 81, 79, 80, 82, 120, 121, 119, 120, 122, 121}
+	B := []float64{100, 101, 99, 100, 102, 98, 100, 101, 99, 100, 100}
+	wB := []float64{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}
+	fastHeavy := []float64{10, 10, 10, 10, 10, 1, 1, 1, 1, 1, 1}
+	slowHeavy := []float64{1, 1, 1, 1, 1, 10, 10, 10, 10, 10, 10}
+
+	fast, err := CompareSamplesWeighted(A, fastHeavy, B, wB, nil, 2000, 7)
+	assert.NoError(t, err)
+	slow, err := CompareSamplesWeighted(A, slowHeavy, B, wB, nil, 2000, 7)
+	assert.NoError(t, err)
+	assert.True(t, fast[0].Confidence > 0.95, "got %v", fast[0].Confidence)
+	assert.True(t, slow[0].Confidence < 0.05, "got %v", slow[0].Confidence)
+	assert.InDelta(t, 0.2, fast[0].ObservedDelta, 1e-12, "weighted median of A is 80")
+	assert.InDelta(t, -0.2, slow[0].ObservedDelta, 1e-12, "weighted median of A is 120")
+}
+
+func TestWeightedMedian(t *testing.T) {
+	values := []float64{5, 1, 4, 2, 3}
+	equal := []float64{1, 1, 1, 1, 1}
+	assert.Equal(t, 3.0, WeightedMedian(values, equal))
+	assert.Equal(t, QuickMedian([]float64{5, 1, 4, 2}), WeightedMedian(values[:4], equal[:4]), "upper median for even length")
+	assert.Equal(t, 5.0, WeightedMedian(values, []float64{10, 1, 1, 1, 1}))
+	assert.Equal(t, 2.0, WeightedMedian(values, []float64{0, 0, 0, 1, 0}))
+	assert.Equal(t, 4.0, WeightedMedian(values, []float64{0.1, 0.1, 0.4, 0.2, 0.2}), "cumulative weight 0.6 > 0.5 is reached at 4")
+	assert.Equal(t, []float64{5, 1, 4, 2, 3}, values, "input must not be modified")
+
+	for name, tc := range map[string][2][]float64{
+		"empty":           {nil, nil},
+		"all-zero":        {values, {0, 0, 0, 0, 0}},
+		"length mismatch": {values, equal[:4]},
+		"negative weight": {values, {1, 1, -1, 1, 1}},
+		"NaN weight":      {values, {1, 1, math.NaN(), 1, 1}},
+		"NaN value":       {{1, math.NaN(), 3}, {1, 1, 1}},
+	} {
+		assert.True(t, math.IsNaN(WeightedMedian(tc[0], tc[1])), name)
+	}
+}
+
+func TestCompareSamplesWeightedValidation(t *testing.T) {
+	A := make([]float64, 11)
+	w := make([]float64, 11)
+	for i := range w {
+		w[i] = 1
+	}
+	_, err := CompareSamplesWeighted(A, w[:10], A, w, nil, 10, 1)
+	assert.ErrorIs(t, err, ErrInvalidWeights, "length mismatch")
+	_, err = CompareSamplesWeighted(A[:10], w[:10], A, w, nil, 10, 1)
+	assert.ErrorIs(t, err, ErrTooFewDataPoints)
+
+	for _, bad := range []float64{-1, math.NaN(), math.Inf(1)} {
+		wBad := append([]float64(nil), w...)
+		wBad[3] = bad
+		_, err = CompareSamplesWeighted(A, w, A, wBad, nil, 10, 1)
+		assert.ErrorIs(t, err, ErrInvalidWeights, "invalid weight %v", bad)
+	}
+	_, err = CompareSamplesWeighted(A, make([]float64, 11), A, w, nil, 10, 1)
+	assert.ErrorIs(t, err, ErrInvalidWeights, "zero total weight")
+
+	results, err := CompareSamplesWeighted(A, w, A, w, nil, 0, 1)
+	assert.NoError(t, err)
+	assert.True(t, math.IsNaN(results[0].Confidence), "expected NaN for resamples == 0")
+
+	results, err = CompareSamplesWeighted(A, w, A, w, nil, 10, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, results[0].Confidence, "identical constant samples always meet threshold 0")
+}